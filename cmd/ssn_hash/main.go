@@ -0,0 +1,726 @@
+// File: main.go
+
+// Command ssn_hash normalizes, validates and hashes Social Security numbers
+// and EINs, one at a time or in bulk, on top of package ssnhash. Run it with
+// -help for the modes and flags.
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// idType bundles the rules for one kind of identifier (-idtype). Every kind
+// shares the same hashing core.
+type idType struct {
+	name      string
+	normalize func(string) (string, error)
+	// normalizeBytes is normalize for input in a byte slice; the result
+	// must be zeroized.
+	normalizeBytes func([]byte) ([]byte, error)
+	validate       func(string) error
+	mask           func(string) string
+}
+
+var idTypes = map[string]idType{
+	"ssn": {name: "ssn", normalize: ssnhash.NormalizeSSN, normalizeBytes: ssnhash.NormalizeSSNBytes, validate: ssnhash.ValidateSSN, mask: ssnhash.MaskSSN},
+	"ein": {name: "ein", normalize: ssnhash.NormalizeEIN, normalizeBytes: ssnhash.NormalizeEINBytes, validate: ssnhash.ValidateEIN, mask: ssnhash.MaskEIN},
+}
+
+// prepareID applies the optional validation step and the default
+// normalization step shared by the single-SSN and batch code paths.
+func prepareID(id string, t idType, raw, validate bool) (string, error) {
+	if validate {
+		if err := t.validate(id); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", strings.ToUpper(t.name), err)
+		}
+	}
+	if raw {
+		return id, nil
+	}
+	return t.normalize(id)
+}
+
+// ssnConfig groups the per-SSN settings shared by every input mode.
+type ssnConfig struct {
+	id       idType
+	salt     []byte // zeroized by main once every record is done
+	genSalt  int    // when > 0, hash each record with a fresh salt of this many bytes
+	oldSalt  []byte // with -salt-old, also hash each record under this salt
+	normSalt bool   // apply NormalizeSalt to salts supplied per request
+	strict   bool   // reject blank per-request salts instead of logging them
+	raw      bool
+	validate bool
+	hash     ssnhash.Config
+	audit    *auditLog // with -audit-log, records every hash produced
+
+	constantWork bool // hash a placeholder before rejecting invalid input
+}
+
+// hashed is the outcome of hashing one SSN.
+type hashed struct {
+	hash string
+	salt string // hex-encoded generated salt with -gen-salt, otherwise ""
+	old  string // hash under the -salt-old salt, otherwise ""
+}
+
+// process validates, normalizes and hashes a single SSN.
+func (c ssnConfig) process(ssn string) (hashed, error) {
+	input, err := prepareID(ssn, c.id, c.raw, c.validate)
+	if err != nil {
+		if c.constantWork {
+			c.spendHashWork()
+		}
+		return hashed{}, err
+	}
+	buf := []byte(input)
+	defer ssnhash.Zeroize(buf)
+	return c.hashPrepared(buf)
+}
+
+// verify reports whether ssn hashes to expected, given in the configured
+// encoding, under the fixed salt. The digests are compared in constant time.
+func (c ssnConfig) verify(ssn, expected string) (bool, error) {
+	input, err := prepareID(ssn, c.id, c.raw, c.validate)
+	if err != nil {
+		if c.constantWork {
+			c.spendVerifyWork(expected)
+		}
+		return false, err
+	}
+	buf := []byte(input)
+	defer ssnhash.Zeroize(buf)
+	sum, err := c.hash.Digest(buf, c.salt)
+	if err != nil {
+		return false, err
+	}
+	ok, err := ssnhash.VerifyDigest(sum, expected, c.hash.Encoding)
+	if err != nil {
+		return false, err
+	}
+	if c.audit != nil {
+		// A check that cannot be audited is not reported.
+		if err := c.audit.recordVerify(c, expected, ok, buf); err != nil {
+			return false, err
+		}
+	}
+	return ok, nil
+}
+
+// hashPrepared hashes an already prepared SSN, first drawing a fresh salt
+// when -gen-salt is in effect, and also under the old salt during a
+// -salt-old rotation.
+func (c ssnConfig) hashPrepared(ssn []byte) (hashed, error) {
+	salt := c.salt
+	var out hashed
+	if c.genSalt > 0 {
+		gen, err := ssnhash.GenerateSalt(c.genSalt)
+		if err != nil {
+			return hashed{}, err
+		}
+		defer ssnhash.Zeroize(gen)
+		salt = gen
+		out.salt = hex.EncodeToString(gen)
+	}
+
+	h, err := c.hash.Hash(ssn, salt)
+	if err != nil {
+		return hashed{}, err
+	}
+	out.hash = h
+	if len(c.oldSalt) > 0 {
+		if out.old, err = c.hash.Hash(ssn, c.oldSalt); err != nil {
+			return hashed{}, err
+		}
+	}
+	if c.audit != nil {
+		// A record that cannot be audited is not returned.
+		if err := c.audit.record(c, out, ssn); err != nil {
+			return hashed{}, err
+		}
+	}
+	return out, nil
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	err := run()
+	if err == nil {
+		return
+	}
+	var u usage
+	switch {
+	case errors.As(err, &u):
+		fmt.Fprintln(os.Stderr, u)
+	case !errors.Is(err, errReported):
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(exitCode(err))
+}
+
+// run is the whole command. Every failure is returned rather than exiting
+// on the spot, so deferred cleanup (zeroizing the salt and pepper, closing
+// the audit log) always runs and main can pick the exit code.
+func run() error {
+	// ── 1. Parse command-line flags ─────────────────────────────
+	var ssns stringList
+	flag.Var(&ssns, "ssn", "Social Security number (digits only or with dashes); repeat to hash several")
+	var fields fieldList
+	flag.Var(&fields, "field", "Multi-field mode: a name:value input to HashFields; repeat in a fixed order (a field named after -idtype is normalized)")
+	tag := flag.String("tag", "", "Multi-field mode: domain-separation tag, e.g. acme/match-key/v1 (required with -field)")
+	idTypeName := flag.String("idtype", "ssn", "Kind of identifier given by -ssn or the input column: ssn or ein")
+	saltFlag := flag.String("salt", "", "Salt value (string); prefer -salt-file or $"+saltEnvVar+" to keep it out of shell history")
+	saltFile := flag.String("salt-file", "", "Read the salt from this file (one trailing newline is ignored)")
+	saltOldFlag := flag.String("salt-old", "", "Salt rotation: also hash every record with this previous salt and output both hashes, labeled old and new")
+	strict := flag.Bool("strict", false, "Fail instead of warning when the salt (or -salt-old, or a -serve request's salt) is empty or whitespace only")
+	normalizeSalt := flag.Bool("normalize-salt", false, "Trim whitespace from the salt and convert it to Unicode NFC (changes hashes; version this decision)")
+	pepperFlag := flag.String("pepper", "", "Application-wide secret mixed in as pepper||salt||ssn (or $"+pepperEnvVar+"); never printed")
+	raw := flag.Bool("raw", false, "Hash the SSN byte-for-byte instead of normalizing it first")
+	validate := flag.Bool("validate", false, "Reject SSNs that violate the SSA area/group/serial rules (EINs: unassigned IRS prefixes)")
+	in := flag.String("in", "", "Batch mode: read SSNs from this CSV file")
+	out := flag.String("out", "", "Write output here instead of stdout; the file is created 0600 and never overwritten without -force")
+	force := flag.Bool("force", false, "Allow -out to overwrite an existing file")
+	column := flag.Int("column", 0, "Batch mode: zero-based index of the SSN column")
+	header := flag.Bool("header", false, "Batch mode: treat the first row as a header")
+	dryRun := flag.Bool("dry-run", false, "Batch mode: check that every row parses, normalizes and validates, without hashing or writing output")
+	checkpointPath := flag.String("checkpoint", "", "Batch mode: record progress in this file and resume from it after a crash (requires -out)")
+	checkpointEvery := flag.Int("checkpoint-every", defaultCheckpointEvery, "Batch mode: rows between durable (fsynced) checkpoints")
+	dedupReport := flag.Bool("dedup-report", false, "Batch mode: report repeated identifiers on stderr (with -show-masked, list them masked)")
+	unique := flag.Bool("unique", false, "Batch mode: write only the first row for each distinct hash and report how many were suppressed")
+	workers := flag.Int("workers", runtime.NumCPU(), "Batch mode: number of hashing goroutines")
+	verifyIn := flag.String("verify-in", "", "Check a CSV of SSN,hash pairs and report each line as match or MISMATCH; exit 1 if any row fails")
+	verify := flag.String("verify", "", "Compare the SSN against this hash (in -encoding); exit 0 on match, 1 on mismatch")
+	algo := flag.String("algo", ssnhash.DefaultAlgo, "Hash algorithm: sha256, sha512, sha512_256 or sha3_256")
+	encoding := flag.String("encoding", ssnhash.DefaultEncoding, "Digest encoding: hex, hexupper, base64 or base64url (unpadded)")
+	framed := flag.Bool("framed", false, "Prefix each field with its 4-byte big-endian length instead of concatenating")
+	sep := flag.String("sep", "", `Separator inserted between salt and SSN before hashing, e.g. ":" for legacy salt+":"+ssn hashes`)
+	rounds := flag.Int("rounds", 1, "Total hash rounds; each extra round computes H(salt || previous digest)")
+	truncate := flag.Int("truncate", 0, "Keep only the first N bytes of the digest, before encoding (default: full length). Collisions become likely after about 2^(4N) distinct SSNs (N=8: ~4 billion, N=4: ~65,000); for non-security bucketing only")
+	useHMAC := flag.Bool("hmac", false, "Compute HMAC(key=salt, message=ssn) instead of hashing salt+ssn")
+	useArgon2 := flag.Bool("argon2", false, "Stretch with Argon2id and print a PHC string ($argon2id$v=19$m=...)")
+	memory := flag.Uint("memory", uint(ssnhash.DefaultArgon2Params.Memory), fmt.Sprintf("Argon2id memory in KiB (minimum %d)", ssnhash.MinArgon2Memory))
+	usePBKDF2 := flag.Bool("pbkdf2", false, "Derive with PBKDF2-HMAC-<algo> and print a PHC-style string ($pbkdf2-sha256$i=...)")
+	iterations := flag.Uint("iterations", 0, fmt.Sprintf("Argon2id passes (default %d, minimum %d) or PBKDF2 iterations (default %d, minimum %d)",
+		ssnhash.DefaultArgon2Params.Iterations, ssnhash.MinArgon2Iterations, ssnhash.DefaultPBKDF2Params.Iterations, ssnhash.MinPBKDF2Iterations))
+	keyLen := flag.Uint("keylen", uint(ssnhash.DefaultPBKDF2Params.KeyLen), fmt.Sprintf("Argon2id or PBKDF2 derived key length in bytes (minimum %d)", ssnhash.MinPBKDF2KeyLen))
+	parallelism := flag.Uint("parallelism", uint(ssnhash.DefaultArgon2Params.Parallelism), "Argon2id lanes (minimum 1)")
+	format := flag.String("format", "text", "Output format: text or json; with -in, fixed reads a fixed-width file instead of CSV")
+	ssnStart := flag.Int("ssn-start", 0, "Batch mode with -format=fixed: zero-based byte offset of the SSN in each line")
+	ssnLen := flag.Int("ssn-len", 9, "Batch mode with -format=fixed: length of the SSN in bytes")
+	hashOnly := flag.Bool("hash-only", false, "Batch mode with -format=fixed: write only the hash for each line instead of appending it")
+	templateText := flag.String("template", "", "Render each result with this Go text/template, e.g. '{{.MaskedSSN}},{{.Hash}}'; fields: Hash, MaskedSSN, Algo, Encoding, GeneratedSalt, OldHash")
+	stdin := flag.Bool("stdin", false, "Stream mode: read one SSN per line from stdin and write one hash per line")
+	interactive := flag.Bool("interactive", false, "Prompt for the SSN (and the salt, if none is configured) on the terminal without echoing it")
+	showMasked := flag.Bool("show-masked", false, "Text output: also print the SSN masked to its last four digits (XXX-XX-6789); with -dedup-report, list duplicates masked")
+	bench := flag.Int("bench", 0, "Hash N random synthetic SSNs with the current settings and report throughput")
+	serve := flag.String("serve", "", "Serve POST /hash on this address (e.g. :8080) instead of hashing once")
+	rate := flag.Int("rate", defaultRate, "Server mode: requests per second allowed per client IP (0 disables the limit)")
+	genSalt := flag.Int("gen-salt", 0, "Hash each record with its own random salt of N bytes, printed (hex) next to the hash; the salt must be stored to verify later")
+	showVersion := flag.Bool("version", false, "Print the version, build commit and hashing defaults, then exit")
+	testVectorsFlag := flag.Bool("test-vectors", false, "Print a table of fixed inputs and their hashes, computed by this binary, for cross-implementation checks")
+	auditPath := flag.String("audit-log", "", "Append one JSON line per hash or verification (time, mode, algo, encoding, hash; never the SSN or salt) to this 0600 file")
+	constantWork := flag.Bool("constant-work", false, "Run the full hash (on a placeholder) even for input that fails normalization or -validate, so rejections take as long as hashes; costs one hash per invalid record")
+	describe := flag.String("describe-flags", "", "Print every flag with its type, default and usage in this format (json) for wrapper scripts, then exit")
+	jobPath := flag.String("job", "", "Load settings from this JSON job file; flags on the command line override it")
+	setUsage()
+	flag.Parse()
+
+	if *showVersion {
+		printVersion(os.Stdout)
+		return nil
+	}
+	if *describe != "" {
+		return describeFlags(os.Stdout, flag.CommandLine, *describe)
+	}
+	if *testVectorsFlag {
+		return runTestVectors(os.Stdout)
+	}
+
+	if *jobPath != "" {
+		job, err := LoadJob(*jobPath)
+		if err == nil {
+			err = applyJob(flag.CommandLine, job)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	salt, err := resolveSalt(*saltFlag, *saltFile)
+	if err != nil {
+		return err
+	}
+	if *interactive && salt == "" && *genSalt == 0 {
+		b, err := readHidden("Salt: ")
+		if err != nil {
+			return err
+		}
+		salt = string(b)
+		ssnhash.Zeroize(b)
+	}
+	saltOld := *saltOldFlag
+	saltGiven, oldGiven := salt != "", saltOld != ""
+	if *normalizeSalt {
+		salt = ssnhash.NormalizeSalt(salt)
+		saltOld = ssnhash.NormalizeSalt(saltOld)
+	}
+	if saltGiven {
+		if err := checkSalt("the salt", salt, *strict); err != nil {
+			return err
+		}
+	}
+	if oldGiven {
+		if err := checkSalt("-salt-old", saltOld, *strict); err != nil {
+			return err
+		}
+	}
+
+	// -format only shapes single-SSN output, so with -in it is free to
+	// select the input format instead.
+	var fixed *fixedField
+	if *format == "fixed" && *in != "" {
+		if *ssnStart < 0 || *ssnLen < 1 {
+			return usageErrorf("-ssn-start must not be negative and -ssn-len must be at least 1")
+		}
+		fixed = &fixedField{start: *ssnStart, len: *ssnLen}
+		*format = "text"
+	} else if *format != "text" && *format != "json" {
+		return usageErrorf("unsupported format %q (want text or json, or fixed with -in)", *format)
+	}
+	var tmpl *template.Template
+	if *templateText != "" {
+		if *format != "text" {
+			return usageErrorf("-template replaces -format and cannot be combined with -format=json")
+		}
+		if *stdin || *serve != "" {
+			return usageErrorf("-template applies to single-SSN and batch (-in) output only")
+		}
+		if tmpl, err = parseOutputTemplate(*templateText); err != nil {
+			return usageError(err)
+		}
+	}
+
+	pepper := []byte(resolvePepper(*pepperFlag))
+	defer ssnhash.Zeroize(pepper)
+
+	hc := ssnhash.Config{Algo: *algo, Encoding: *encoding, HMAC: *useHMAC, Pepper: pepper, Framed: *framed, Sep: []byte(*sep), Rounds: *rounds, Truncate: *truncate}
+	size, err := ssnhash.DigestSize(hc.Algo)
+	if err != nil {
+		return usageError(err)
+	}
+	if hc.Truncate < 0 || hc.Truncate > size {
+		return usageErrorf("-truncate must be between 1 and %d, the %s digest size in bytes", size, hc.Algo)
+	}
+	if hc.Truncate > 0 && (*useArgon2 || *usePBKDF2) {
+		return usageErrorf("-truncate does not apply to -argon2 or -pbkdf2; use -keylen")
+	}
+	if len(hc.Sep) > 0 && (*useHMAC || *useArgon2 || *usePBKDF2) {
+		return usageErrorf("-sep only applies to the plain salt+ssn construction, not -hmac, -argon2 or -pbkdf2")
+	}
+	if *keyLen > ssnhash.MaxKeyLen {
+		return usageErrorf("-keylen must be at most %d bytes", ssnhash.MaxKeyLen)
+	}
+	if hc.Rounds < 1 {
+		return usageErrorf("-rounds must be at least 1")
+	}
+	if _, err := ssnhash.EncodeDigest(nil, hc.Encoding); err != nil {
+		return usageError(err)
+	}
+	if *useArgon2 {
+		if *useHMAC {
+			return usageErrorf("-argon2 and -hmac are mutually exclusive")
+		}
+		if hc.Rounds > 1 {
+			return usageErrorf("-rounds does not apply to -argon2; use -iterations")
+		}
+		if hc.Encoding != ssnhash.DefaultEncoding {
+			return usageErrorf("-encoding does not apply to -argon2, which always emits a PHC string")
+		}
+		if *parallelism > 255 {
+			return usageErrorf("argon2 parallelism must be at most 255")
+		}
+		// Argon2 takes 32-bit costs; a larger value would wrap around and
+		// could slip under the minimums below.
+		if *memory > math.MaxUint32 || *iterations > math.MaxUint32 {
+			return usageErrorf("argon2 -memory and -iterations must be at most %d", uint32(math.MaxUint32))
+		}
+		params := ssnhash.DefaultArgon2Params
+		params.Memory = uint32(*memory)
+		if *iterations != 0 {
+			params.Iterations = uint32(*iterations)
+		}
+		params.Parallelism = uint8(*parallelism)
+		params.KeyLen = uint32(*keyLen)
+		if err := params.Validate(); err != nil {
+			return usageError(err)
+		}
+		if *genSalt > 0 && *genSalt < ssnhash.MinArgon2SaltLen {
+			return usageErrorf("argon2 needs -gen-salt of at least %d bytes", ssnhash.MinArgon2SaltLen)
+		}
+		if salt != "" && len(salt) < ssnhash.MinArgon2SaltLen {
+			return usageErrorf("argon2 salt must be at least %d bytes", ssnhash.MinArgon2SaltLen)
+		}
+		hc.Argon2 = &params
+	}
+	if *usePBKDF2 {
+		if *useHMAC || *useArgon2 {
+			return usageErrorf("-pbkdf2 cannot be combined with -hmac or -argon2")
+		}
+		if hc.Rounds > 1 {
+			return usageErrorf("-rounds does not apply to -pbkdf2; use -iterations")
+		}
+		if hc.Encoding != ssnhash.DefaultEncoding {
+			return usageErrorf("-encoding does not apply to -pbkdf2, which always emits a PHC-style string")
+		}
+		params := ssnhash.DefaultPBKDF2Params
+		if *iterations != 0 {
+			params.Iterations = int(*iterations)
+		}
+		params.KeyLen = int(*keyLen)
+		if err := params.Validate(); err != nil {
+			return usageError(err)
+		}
+		if *genSalt > 0 && *genSalt < ssnhash.MinPBKDF2SaltLen {
+			return usageErrorf("pbkdf2 needs -gen-salt of at least %d bytes", ssnhash.MinPBKDF2SaltLen)
+		}
+		if salt != "" && len(salt) < ssnhash.MinPBKDF2SaltLen {
+			return usageErrorf("pbkdf2 salt must be at least %d bytes", ssnhash.MinPBKDF2SaltLen)
+		}
+		hc.PBKDF2 = &params
+	}
+
+	if *genSalt < 0 || *genSalt > ssnhash.MaxSaltLen {
+		return usageErrorf("-gen-salt must be positive and at most %d bytes", ssnhash.MaxSaltLen)
+	}
+	if *genSalt > 0 && salt != "" {
+		return usageErrorf("-gen-salt cannot be combined with -salt, -salt-file or $%s", saltEnvVar)
+	}
+	if saltOld != "" {
+		if salt == "" {
+			return usageErrorf("-salt-old needs the new salt from -salt, -salt-file or $%s", saltEnvVar)
+		}
+		if *stdin || *serve != "" || *verify != "" || *verifyIn != "" || *bench != 0 {
+			return usageErrorf("-salt-old applies to single-SSN, -interactive and batch (-in) output only")
+		}
+		if hc.Argon2 != nil && len(saltOld) < ssnhash.MinArgon2SaltLen {
+			return usageErrorf("argon2 salt must be at least %d bytes", ssnhash.MinArgon2SaltLen)
+		}
+		if hc.PBKDF2 != nil && len(saltOld) < ssnhash.MinPBKDF2SaltLen {
+			return usageErrorf("pbkdf2 salt must be at least %d bytes", ssnhash.MinPBKDF2SaltLen)
+		}
+		if saltOld == salt {
+			fmt.Fprintln(os.Stderr, "Warning: -salt-old is the same as the new salt; both hashes will be identical")
+		}
+	}
+	haveSalt := salt != "" || *genSalt > 0
+
+	saltBuf := []byte(salt)
+	defer ssnhash.Zeroize(saltBuf)
+	oldSaltBuf := []byte(saltOld)
+	defer ssnhash.Zeroize(oldSaltBuf)
+	id, ok := idTypes[*idTypeName]
+	if !ok {
+		return usageErrorf("unsupported idtype %q (want ssn or ein)", *idTypeName)
+	}
+
+	sc := ssnConfig{
+		id:       id,
+		salt:     saltBuf,
+		genSalt:  *genSalt,
+		oldSalt:  oldSaltBuf,
+		normSalt: *normalizeSalt,
+		strict:   *strict,
+		raw:      *raw,
+		validate: *validate,
+		hash:     hc,
+
+		constantWork: *constantWork,
+	}
+	if *auditPath != "" {
+		mode := "single"
+		switch {
+		case *serve != "":
+			mode = "server"
+		case *in != "":
+			mode = "batch"
+		case *verifyIn != "":
+			mode = "verify-batch"
+		case *stdin:
+			mode = "stream"
+		case len(fields) > 0:
+			mode = "field"
+		case *interactive:
+			mode = "interactive"
+		case *verify != "":
+			mode = "verify"
+		}
+		audit, err := openAuditLog(*auditPath, mode)
+		if err != nil {
+			return err
+		}
+		defer audit.Close()
+		sc.audit = audit
+	}
+
+	// ── 2. Bench, server, batch and stream modes ────────────────
+	if *bench != 0 {
+		if *out != "" {
+			return usageErrorf("-bench reports to stdout and does not take -out")
+		}
+		return runBench(os.Stdout, *bench, sc)
+	}
+
+	if *serve != "" {
+		if *out != "" {
+			return usageErrorf("-serve answers over HTTP and does not take -out")
+		}
+		if *genSalt > 0 {
+			return usageErrorf("-serve takes the salt from each request and cannot use -gen-salt")
+		}
+		if *rate < 0 {
+			return usageErrorf("-rate must not be negative")
+		}
+		return runServer(*serve, sc, *rate)
+	}
+
+	if *in != "" {
+		if !haveSalt && !*dryRun {
+			return usage("Usage: ssn_hash -in=<file.csv> -salt=<salt>|-salt-file=<path>|-gen-salt=N [-out=<file.csv>] [-column=N] [-header]")
+		}
+		return runBatch(batchConfig{
+			in:      *in,
+			out:     *out,
+			force:   *force,
+			column:  *column,
+			header:  *header,
+			workers: *workers,
+			ssn:     sc,
+
+			dryRun:      *dryRun,
+			dedupReport: *dedupReport,
+			dedupMasked: *showMasked,
+			unique:      *unique,
+			template:    tmpl,
+
+			fixed:    fixed,
+			hashOnly: *hashOnly,
+
+			checkpoint:      *checkpointPath,
+			checkpointEvery: *checkpointEvery,
+		})
+	}
+
+	if *verifyIn != "" {
+		if *genSalt > 0 || hc.KDF() {
+			return usageErrorf("-verify-in needs the original salt and does not support -gen-salt, -argon2 or -pbkdf2")
+		}
+		if !haveSalt {
+			return usage("Usage: ssn_hash -verify-in=<file.csv> -salt=<salt>|-salt-file=<path> [-header]")
+		}
+		var counts verifyCounts
+		err := withOutput(*out, *force, func(w io.Writer) error {
+			var err error
+			counts, err = runVerifyBatch(*verifyIn, *header, w, sc)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !counts.ok() {
+			return reported(exitFailure)
+		}
+		return nil
+	}
+
+	if *stdin {
+		if !haveSalt {
+			return usage("Usage: ssn_hash -stdin -salt=<salt>|-salt-file=<path>|-gen-salt=N < ssns.txt")
+		}
+		return withOutput(*out, *force, func(w io.Writer) error {
+			return runStream(os.Stdin, w, sc)
+		})
+	}
+
+	if len(fields) > 0 {
+		if len(ssns) > 0 || *interactive || *verify != "" {
+			return usageErrorf("-field replaces -ssn and cannot be combined with -ssn, -interactive or -verify")
+		}
+		if *tag == "" {
+			return usageErrorf("-field needs a domain-separation -tag")
+		}
+		plainSHA256 := hc.Algo == ssnhash.DefaultAlgo && hc.Encoding == ssnhash.DefaultEncoding && !hc.HMAC && !hc.KDF() &&
+			hc.Rounds == 1 && hc.Truncate == 0 && !hc.Framed && len(hc.Sep) == 0 && len(hc.Pepper) == 0
+		if !plainSHA256 || *genSalt > 0 || saltOld != "" || *format != "text" || tmpl != nil {
+			return usageErrorf("-field uses the fixed HashFields construction (SHA-256, hex, text output) and takes no other hashing or output options")
+		}
+		if !haveSalt {
+			return usage("Usage: ssn_hash -field=<name:value> [-field=<name:value>...] -tag=<tag> -salt=<salt>|-salt-file=<path>")
+		}
+		return withOutput(*out, *force, func(w io.Writer) error {
+			return runFields(w, *tag, fields, sc)
+		})
+	}
+
+	if *interactive {
+		if len(ssns) > 0 || *verify != "" {
+			return usageErrorf("-interactive reads the SSN from the terminal and cannot be combined with -ssn or -verify")
+		}
+		return withOutput(*out, *force, func(w io.Writer) error {
+			return runInteractive(w, sc, *format, tmpl, *showMasked)
+		})
+	}
+
+	if len(ssns) == 0 || !haveSalt {
+		return usage("Usage: ssn_hash -ssn=<SSN> [-ssn=<SSN>...] -salt=<salt>|-salt-file=<path>|-gen-salt=N [-raw] [-validate] [-verify=<hash>]")
+	}
+
+	// ── 3. Verify mode ──────────────────────────────────────────
+	if *verify != "" {
+		if len(ssns) != 1 {
+			return usageErrorf("-verify takes exactly one -ssn")
+		}
+		if *genSalt > 0 {
+			return usageErrorf("-verify needs the original salt, not -gen-salt")
+		}
+		if hc.KDF() {
+			return usageErrorf("-verify does not support -argon2 or -pbkdf2")
+		}
+		var ok bool
+		err := withOutput(*out, *force, func(w io.Writer) error {
+			var err error
+			if ok, err = sc.verify(ssns[0], *verify); err != nil {
+				return err
+			}
+			result := "match"
+			if !ok {
+				result = "no match"
+			}
+			_, err = fmt.Fprintln(w, result)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return reported(exitFailure)
+		}
+		return nil
+	}
+
+	// ── 4. Hash and print each SSN ──────────────────────────────
+	// An invalid SSN is reported and skipped; the exit status is that of
+	// the first failure.
+	var dst io.Writer = os.Stdout
+	var outFile *os.File
+	if *out != "" {
+		outFile, err = createSecureFile(*out, *force)
+		if err != nil {
+			return err
+		}
+		dst = outFile
+	}
+
+	var failed error
+	for i, s := range ssns {
+		index, prefix := 0, ""
+		if len(ssns) > 1 {
+			index, prefix = i+1, fmt.Sprintf("[%d] ", i+1)
+		}
+		if err := printHash(dst, s, index, sc, *format, tmpl, *showMasked); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s%v\n", prefix, err)
+			if failed == nil {
+				failed = reported(exitCode(err))
+			}
+		}
+	}
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: writing output file:", err)
+			if failed == nil {
+				failed = reported(exitIO)
+			}
+		}
+	}
+	return failed
+}
+
+// printHash validates, normalizes and hashes one SSN given on the command
+// line and writes the result to w. A non-zero index is the SSN's position
+// among several -ssn flags: text lines start with "[index] " and JSON
+// results carry it in Index. By default 123-45-6789 and 123456789 hash to the same value; -raw hashes
+// the input verbatim and -validate enforces the SSA rules. A non-nil tmpl
+// replaces the -format output with one rendered line.
+func printHash(w io.Writer, ssn string, index int, sc ssnConfig, format string, tmpl *template.Template, showMasked bool) error {
+	h, err := sc.process(ssn)
+	if err != nil {
+		return err
+	}
+	return printHashed(w, ssn, index, sc, format, tmpl, showMasked, h)
+}
+
+// printHashed writes the already computed hash h of ssn, which is used only
+// to mask it for -show-masked and -template.
+func printHashed(w io.Writer, ssn string, index int, sc ssnConfig, format string, tmpl *template.Template, showMasked bool, h hashed) error {
+	prefix := ""
+	if index > 0 {
+		prefix = fmt.Sprintf("[%d] ", index)
+	}
+	if tmpl != nil {
+		line, err := renderTemplate(tmpl, sc.templateData(ssn, h))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", prefix, line); err != nil {
+			return err
+		}
+		if h.salt != "" {
+			fmt.Fprintln(os.Stderr, storeSaltNotice)
+		}
+		return nil
+	}
+
+	if showMasked && format == "text" {
+		if masked := sc.id.mask(ssn); masked != "" {
+			fmt.Fprintf(w, "%s%s = %s\n", prefix, strings.ToUpper(sc.id.name), masked)
+		}
+	}
+	result := Result{IDType: sc.id.name, Algo: sc.hash.AlgoName(), Encoding: sc.hash.Encoding, Hash: h.hash, Normalized: !sc.raw, GeneratedSalt: h.salt, Index: index}
+	if sc.hash.KDF() {
+		result.Encoding = ""
+	}
+	if sc.hash.Rounds > 1 {
+		result.Rounds = sc.hash.Rounds
+	}
+	result.Truncate = sc.hash.Truncate
+	label := sc.hash.Label(sc.id.name)
+	if h.old == "" {
+		return writeResult(w, format, prefix, label, result)
+	}
+	// Salt rotation: the old-salt hash first, then the new one.
+	old := result
+	old.Hash, old.SaltVersion = h.old, "old"
+	if err := writeResult(w, format, prefix+"old ", label, old); err != nil {
+		return err
+	}
+	result.SaltVersion = "new"
+	return writeResult(w, format, prefix+"new ", label, result)
+}
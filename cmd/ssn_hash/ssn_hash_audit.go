@@ -0,0 +1,117 @@
+// File: ssn_hash_audit.go
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditEntry is one line of the -audit-log. Like Result it has no field
+// that could hold the SSN or a salt, and newAuditEntry, its only
+// constructor, is never given either.
+type auditEntry struct {
+	Time     string `json:"time"` // RFC 3339, UTC
+	Mode     string `json:"mode"` // single, batch, stream, server, interactive, field, verify or verify-batch
+	IDType   string `json:"idtype"`
+	Algo     string `json:"algo"`
+	Encoding string `json:"encoding,omitempty"`
+	Hash     string `json:"hash"`
+	OldHash  string `json:"old_hash,omitempty"`
+	Match    *bool  `json:"match,omitempty"` // -verify and -verify-in only: whether the hash matched
+}
+
+// newAuditEntry describes one hashing operation. It takes only the
+// configuration and the finished hashes; the per-record salt drawn by
+// -gen-salt is deliberately left out along with the configured one.
+func newAuditEntry(mode string, c ssnConfig, h hashed) auditEntry {
+	e := auditEntry{
+		Time:     time.Now().UTC().Format(time.RFC3339Nano),
+		Mode:     mode,
+		IDType:   c.id.name,
+		Algo:     c.hash.AlgoName(),
+		Encoding: c.hash.Encoding,
+		Hash:     h.hash,
+		OldHash:  h.old,
+	}
+	if c.hash.KDF() {
+		e.Encoding = ""
+	}
+	return e
+}
+
+// errAuditLeak is returned, and nothing is written, if an audit line would
+// contain the identifier it describes, the salt or the pepper.
+var errAuditLeak = errors.New("audit log entry would contain the identifier or a secret; refusing to write it")
+
+// minGuardLen is the shortest identifier or secret the leak guard looks
+// for. Shorter ones (a raw partial SSN, a one-letter salt) would match
+// hashes and field names by chance.
+const minGuardLen = 9
+
+// auditLog appends JSON lines to the -audit-log file. It is shared by every
+// worker, so writes are serialized.
+type auditLog struct {
+	mode string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// openAuditLog opens path for appending, creating it with 0600 permissions
+// if needed. mode is recorded in every entry.
+func openAuditLog(path, mode string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return &auditLog{mode: mode, f: f}, nil
+}
+
+// record appends the entry for one hashed identifier. ssn is the prepared
+// identifier that was hashed.
+func (l *auditLog) record(c ssnConfig, h hashed, ssn []byte) error {
+	return l.write(c, newAuditEntry(l.mode, c, h), ssn)
+}
+
+// recordVerify appends the entry for one -verify or -verify-in check of
+// ssn against expected.
+func (l *auditLog) recordVerify(c ssnConfig, expected string, match bool, ssn []byte) error {
+	e := newAuditEntry(l.mode, c, hashed{hash: expected})
+	e.Match = &match
+	return l.write(c, e, ssn)
+}
+
+// write appends e as one JSON line. As a last-line guard the line is
+// refused if the bytes of ssn, the salt, the old salt or the pepper show up
+// anywhere in it. The line is handed to the file in a single unbuffered
+// write, so each record reaches the log before the next one is hashed.
+func (l *auditLog) write(c ssnConfig, e auditEntry, ssn []byte) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	for _, secret := range [][]byte{ssn, c.salt, c.oldSalt, c.hash.Pepper} {
+		if len(secret) >= minGuardLen && bytes.Contains(line, secret) {
+			return errAuditLeak
+		}
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(line); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file.
+func (l *auditLog) Close() error {
+	return l.f.Close()
+}
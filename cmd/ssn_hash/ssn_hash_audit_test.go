@@ -0,0 +1,158 @@
+// File: ssn_hash_audit_test.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+const (
+	auditSSN  = "123-45-6789"
+	auditSalt = "audit-test-salt"
+)
+
+// auditConfig returns the default settings with an audit log at path
+// recording mode.
+func auditConfig(t *testing.T, path, mode string) ssnConfig {
+	t.Helper()
+	audit, err := openAuditLog(path, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { audit.Close() })
+	return ssnConfig{
+		id:    idTypes["ssn"],
+		salt:  []byte(auditSalt),
+		hash:  ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding, Rounds: 1},
+		audit: audit,
+	}
+}
+
+// TestAuditLogModes runs every mode that can be driven without a terminal
+// against one audit log, then checks that each mode left an entry and that
+// no line holds the SSN, in either form, or the salt. -interactive shares
+// hashPrepared with the single-SSN path but needs a real terminal.
+func TestAuditLogModes(t *testing.T) {
+	quietStderr(t)
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	hash := ssnhash.HashSSN("123456789", auditSalt)
+
+	modes := []struct {
+		mode string
+		run  func(sc ssnConfig) error
+	}{
+		{"single", func(sc ssnConfig) error {
+			return printHash(io.Discard, auditSSN, 0, sc, "text", nil, false)
+		}},
+		{"batch", func(sc ssnConfig) error {
+			return runBatch(batchConfig{
+				in:      writeFile("in.csv", auditSSN+",x\n"),
+				out:     filepath.Join(dir, "out.csv"),
+				workers: 1,
+				ssn:     sc,
+			})
+		}},
+		{"stream", func(sc ssnConfig) error {
+			return runStream(strings.NewReader(auditSSN+"\n"), io.Discard, sc)
+		}},
+		{"server", func(sc ssnConfig) error {
+			body := `{"ssn":"` + auditSSN + `","salt":"` + auditSalt + `"}`
+			rec := httptest.NewRecorder()
+			newHashHandler(sc).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hash", strings.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				return errors.New(rec.Body.String())
+			}
+			return nil
+		}},
+		{"field", func(sc ssnConfig) error {
+			fields := []ssnhash.Field{{Name: "ssn", Value: auditSSN}, {Name: "dob", Value: "1990-01-31"}}
+			return runFields(io.Discard, "example/v1", fields, sc)
+		}},
+		{"verify", func(sc ssnConfig) error {
+			ok, err := sc.verify(auditSSN, hash)
+			if err == nil && !ok {
+				err = errors.New("no match")
+			}
+			return err
+		}},
+		{"verify-batch", func(sc ssnConfig) error {
+			_, err := runVerifyBatch(writeFile("verify.csv", auditSSN+","+hash+"\n"), false, io.Discard, sc)
+			return err
+		}},
+	}
+	for _, m := range modes {
+		if err := m.run(auditConfig(t, logPath, m.mode)); err != nil {
+			t.Fatalf("%s: %v", m.mode, err)
+		}
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	seen := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		for _, secret := range []string{auditSSN, "123456789", auditSalt} {
+			if strings.Contains(line, secret) {
+				t.Errorf("audit line contains %q: %s", secret, line)
+			}
+		}
+		var e auditEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad audit line %q: %v", line, err)
+		}
+		seen[e.Mode] = true
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range modes {
+		if !seen[m.mode] {
+			t.Errorf("no audit entry for mode %s", m.mode)
+		}
+	}
+}
+
+// TestAuditRefusesSecrets checks the leak guard: an entry that would
+// contain the salt, the old salt or the pepper is not written.
+func TestAuditRefusesSecrets(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	h := hashed{hash: ssnhash.HashSSN(auditSSN, auditSalt)}
+	leak := []byte(h.hash[:16])
+	for name, set := range map[string]func(*ssnConfig){
+		"salt":     func(c *ssnConfig) { c.salt = leak },
+		"old salt": func(c *ssnConfig) { c.oldSalt = leak },
+		"pepper":   func(c *ssnConfig) { c.hash.Pepper = leak },
+	} {
+		sc := auditConfig(t, logPath, "single")
+		set(&sc)
+		if err := sc.audit.record(sc, h, []byte("123456789")); !errors.Is(err, errAuditLeak) {
+			t.Errorf("%s in the entry: record = %v, want errAuditLeak", name, err)
+		}
+	}
+	if info, err := os.Stat(logPath); err != nil || info.Size() != 0 {
+		t.Errorf("audit log after refused entries: %v, %v", info, err)
+	}
+}
@@ -0,0 +1,477 @@
+// File: ssn_hash_batch.go
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"text/template"
+)
+
+// batchConfig holds the settings for a CSV batch run (-in).
+type batchConfig struct {
+	in      string
+	out     string
+	force   bool // overwrite an existing cfg.out
+	column  int
+	header  bool
+	workers int
+	ssn     ssnConfig
+
+	dryRun      bool // validate only; compute and write no hashes
+	dedupReport bool // report repeated identifiers on stderr
+	dedupMasked bool // list the masked form of each repeated identifier
+	unique      bool // write each distinct hash only once
+
+	template *template.Template // when set, renders the appended column
+
+	fixed    *fixedField // when set, the input is fixed-width text, not CSV
+	hashOnly bool        // fixed-width output: the hash alone, not the line
+
+	checkpoint      string // resumable run: path of the checkpoint file
+	checkpointEvery int    // rows between checkpoints
+}
+
+// batchJob is one CSV row on its way through the worker pool.
+type batchJob struct {
+	line   int
+	record []string
+	result chan batchResult // buffered; receives exactly one value
+}
+
+type batchResult struct {
+	hashed
+	err error
+}
+
+// scanRows reads CSV rows from r until EOF. Rows that fail to parse or
+// lack the SSN column are passed to bad with their line number and a
+// reason; every other row goes to row, which may return false to stop early.
+func scanRows(r *csv.Reader, column int, row func(line int, record []string) bool, bad func(line int, reason string)) error {
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				bad(perr.StartLine, fmt.Sprintf("malformed row: %v", perr.Err))
+				continue
+			}
+			return fmt.Errorf("reading input: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+
+		if column < 0 || column >= len(record) {
+			bad(line, fmt.Sprintf("row with %d columns (SSN column is %d)", len(record), column))
+			continue
+		}
+		if !row(line, record) {
+			return nil
+		}
+	}
+}
+
+// readBatchRows reads CSV rows from r and hands each hashable one to both
+// jobs and queue. Rows that fail to parse or lack the SSN column are logged
+// and counted as skipped, and rows starting at or before resumeLine (already
+// handled by an earlier run) are passed over silently. It stops early if
+// quit is closed.
+func readBatchRows(r *csv.Reader, column, resumeLine int, jobs, queue chan<- *batchJob, quit <-chan struct{}) (skipped int, err error) {
+	err = scanRows(r, column, func(line int, record []string) bool {
+		if line <= resumeLine {
+			return true
+		}
+		job := &batchJob{line: line, record: record, result: make(chan batchResult, 1)}
+		select {
+		case queue <- job:
+		case <-quit:
+			return false
+		}
+		jobs <- job
+		return true
+	}, func(line int, reason string) {
+		if line <= resumeLine {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "line %d: skipping %s\n", line, reason)
+		skipped++
+	})
+	return skipped, err
+}
+
+// maxDryRunLines caps how many invalid line numbers -dry-run lists.
+const maxDryRunLines = 10
+
+// runDryRun walks cfg.in applying the same parsing, normalization and
+// validation as a real run, but computes and writes no hashes. It reports
+// the number of valid and invalid rows and the first few invalid lines.
+func runDryRun(cfg batchConfig) error {
+	inFile, err := openMaybeGzip(cfg.in, false)
+	if err != nil {
+		return fmt.Errorf("opening input: %w", err)
+	}
+	defer inFile.Close()
+
+	r := csv.NewReader(inFile)
+	r.FieldsPerRecord = -1
+	if cfg.header {
+		if _, err := r.Read(); err != nil && err != io.EOF {
+			return fmt.Errorf("reading header: %w", err)
+		}
+	}
+
+	var valid, invalid int
+	var firstInvalid []string
+	bad := func(line int, reason string) {
+		invalid++
+		if len(firstInvalid) < maxDryRunLines {
+			firstInvalid = append(firstInvalid, fmt.Sprintf("line %d: %s", line, reason))
+		}
+	}
+	err = scanRows(r, cfg.column, func(line int, record []string) bool {
+		if _, err := prepareID(record[cfg.column], cfg.ssn.id, cfg.ssn.raw, cfg.ssn.validate); err != nil {
+			bad(line, err.Error())
+		} else {
+			valid++
+		}
+		return true
+	}, bad)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Dry run: %d valid rows, %d invalid rows; no hashes were computed\n", valid, invalid)
+	for _, msg := range firstInvalid {
+		fmt.Fprintln(os.Stderr, "  "+msg)
+	}
+	if invalid > len(firstInvalid) {
+		fmt.Fprintf(os.Stderr, "  ... and %d more\n", invalid-len(firstInvalid))
+	}
+	return nil
+}
+
+// runBatch hashes the SSN column of every row in cfg.in and writes each row,
+// with the hash appended as a new column, to cfg.out (or stdout). Malformed
+// or invalid rows are logged to stderr with their line number and skipped.
+// Hashing is spread over cfg.workers goroutines; output order always matches
+// input order. Either file is gzip-compressed when its name ends in ".gz".
+func runBatch(cfg batchConfig) error {
+	if cfg.unique && cfg.ssn.genSalt > 0 {
+		return usageErrorf("-unique has no effect with -gen-salt, which gives every row a different hash")
+	}
+	if cfg.fixed != nil {
+		return runFixed(cfg)
+	}
+	if cfg.dryRun {
+		return runDryRun(cfg)
+	}
+	if cfg.checkpoint != "" && cfg.out == "" {
+		// Resuming truncates the output back to the last checkpoint, which
+		// stdout cannot do.
+		return usageErrorf("-checkpoint needs -out")
+	}
+	if cfg.checkpoint != "" && isGzipPath(cfg.out) {
+		// Resuming truncates the output back to the last checkpoint, which
+		// would leave a gzip stream cut off mid-block.
+		return usageErrorf("-checkpoint cannot be used with gzip (.gz) output")
+	}
+	if cfg.unique && cfg.checkpoint != "" {
+		// The set of written hashes is not part of the checkpoint, so a
+		// resumed run would repeat hashes written before the crash.
+		return usageErrorf("-unique cannot be combined with -checkpoint")
+	}
+
+	inFile, err := openMaybeGzip(cfg.in, false)
+	if err != nil {
+		return fmt.Errorf("opening input: %w", err)
+	}
+	defer inFile.Close()
+
+	var ckpt *checkpointer
+	var resuming bool
+	resumeLine := 0
+	var resumeOffset int64
+	if cfg.checkpoint != "" {
+		fp, err := inputFingerprint(cfg.in)
+		if err != nil {
+			return err
+		}
+		prev, err := loadCheckpoint(cfg.checkpoint)
+		if err != nil {
+			return err
+		}
+		if prev != nil {
+			if prev.Fingerprint != fp {
+				return fmt.Errorf("checkpoint %s was written for a different input; refusing to resume (delete it to start over)", cfg.checkpoint)
+			}
+			resuming, resumeLine, resumeOffset = true, prev.Line, prev.OutOffset
+			fmt.Fprintf(os.Stderr, "Resuming after line %d\n", resumeLine)
+		}
+		every := cfg.checkpointEvery
+		if every < 1 {
+			every = defaultCheckpointEvery
+		}
+		ckpt = &checkpointer{path: cfg.checkpoint, every: every, state: checkpoint{Fingerprint: fp, Line: resumeLine}}
+	}
+
+	batch := newBatchRun(cfg)
+	var dst io.Writer
+	var outFile *os.File // synced by checkpoints; never gzip-compressed
+	if resuming {
+		if outFile, err = reopenOutput(cfg.out, resumeOffset); err != nil {
+			return fmt.Errorf("creating output: %w", err)
+		}
+		defer outFile.Close()
+		dst = outFile
+	} else {
+		if dst, err = batch.createOutput(); err != nil {
+			return err
+		}
+		defer batch.closeOutput()
+		if batch.gzOut != nil {
+			outFile = batch.gzOut.f
+		}
+	}
+
+	r := csv.NewReader(inFile)
+	r.FieldsPerRecord = -1
+	w := csv.NewWriter(dst)
+
+	if cfg.header {
+		record, err := r.Read()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading header: %w", err)
+		}
+		if err == nil && !resuming {
+			col := cfg.ssn.hash.ColumnName()
+			if cfg.ssn.id.name != "ssn" {
+				col = cfg.ssn.id.name + "_" + col
+			}
+			if len(cfg.ssn.oldSalt) > 0 && cfg.template == nil {
+				record = append(record, col+"_old")
+				col += "_new"
+			}
+			record = append(record, col)
+			if cfg.ssn.genSalt > 0 {
+				record = append(record, "salt_hex")
+			}
+			if err := w.Write(record); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+		}
+	}
+	if ckpt != nil && !resuming {
+		// Checkpoint the fresh output right away, so a run killed before
+		// its first -checkpoint-every rows still resumes instead of
+		// tripping over the output file it created.
+		if err := ckpt.flush(w, outFile); err != nil {
+			return err
+		}
+	}
+
+	workers := cfg.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Rows flow reader → jobs → workers, while queue carries the same jobs
+	// in input order to the writer below. Each job has its own result
+	// channel, so the writer can wait for rows in order no matter which
+	// worker finishes first, and the bounded queue caps how many rows are
+	// in flight at once.
+	jobs := make(chan *batchJob, workers)
+	queue := make(chan *batchJob, 4*workers)
+	quit := make(chan struct{})
+	defer close(quit)
+
+	var readErr error
+	var readSkipped int
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+		readSkipped, readErr = readBatchRows(r, cfg.column, resumeLine, jobs, queue, quit)
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range jobs {
+				h, err := cfg.ssn.process(job.record[cfg.column])
+				job.result <- batchResult{hashed: h, err: err}
+			}
+		}()
+	}
+
+	for job := range queue {
+		if err := batch.writeRow(w, job); err != nil {
+			return err
+		}
+		if ckpt != nil {
+			if err := ckpt.advance(job.line, w, outFile); err != nil {
+				return err
+			}
+		}
+	}
+	if readErr != nil {
+		return readErr
+	}
+	batch.skipped += readSkipped
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := batch.closeOutput(); err != nil {
+		return err
+	}
+	if ckpt != nil {
+		// The run is complete, so a rerun should start from scratch.
+		if err := os.Remove(cfg.checkpoint); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing checkpoint: %w", err)
+		}
+	}
+	batch.report("rows")
+	return nil
+}
+
+// batchRun is the state shared by CSV and fixed-width batch runs: the
+// output file, the -dedup-report and -unique trackers, and the counts
+// reported at the end.
+type batchRun struct {
+	cfg    batchConfig
+	gzOut  *gzipFile // nil when writing to stdout or resuming
+	dedup  *dedupTracker
+	unique *uniqueFilter
+
+	processed, skipped int
+}
+
+func newBatchRun(cfg batchConfig) *batchRun {
+	b := &batchRun{cfg: cfg}
+	if cfg.dedupReport {
+		b.dedup = newDedupTracker(cfg.dedupMasked)
+	}
+	if cfg.unique {
+		b.unique = newUniqueFilter()
+	}
+	return b
+}
+
+// createOutput creates cfg.out with openMaybeGzip, or returns stdout when
+// no -out was given. With -force an existing output is removed first, so
+// the new file is still created 0600 from scratch.
+func (b *batchRun) createOutput() (io.Writer, error) {
+	if b.cfg.out == "" {
+		return os.Stdout, nil
+	}
+	if b.cfg.force {
+		if err := os.Remove(b.cfg.out); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("creating output file: %w", err)
+		}
+	}
+	gz, err := openMaybeGzip(b.cfg.out, true)
+	if err != nil {
+		return nil, err
+	}
+	b.gzOut = gz
+	return gz, nil
+}
+
+// closeOutput closes the file opened by createOutput, if any. Closing
+// writes the gzip trailer, so its error matters; later calls return nil,
+// so it may also be deferred.
+func (b *batchRun) closeOutput() error {
+	if b.gzOut == nil {
+		return nil
+	}
+	if err := b.gzOut.Close(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// keep feeds the record whose identifier id hashed to h to -dedup-report
+// and reports whether it should be written: with -unique, a hash that was
+// already written is dropped.
+func (b *batchRun) keep(id string, h hashed) bool {
+	if b.dedup != nil {
+		if normalized, err := prepareID(id, b.cfg.ssn.id, b.cfg.ssn.raw, false); err == nil {
+			b.dedup.add(normalized, b.cfg.ssn.id.mask(id))
+		}
+	}
+	return b.unique == nil || b.unique.keep(h.hash)
+}
+
+// report writes the end-of-run summary to stderr; unit names the records,
+// "rows" or "lines".
+func (b *batchRun) report(unit string) {
+	fmt.Fprintf(os.Stderr, "Processed %d %s, skipped %d\n", b.processed, unit, b.skipped)
+	if b.unique != nil {
+		fmt.Fprintf(os.Stderr, "Unique: suppressed %d %s whose hash was already written\n", b.unique.suppressed, unit)
+	}
+	if b.dedup != nil {
+		b.dedup.report(os.Stderr)
+	}
+	if b.cfg.ssn.genSalt > 0 {
+		fmt.Fprintln(os.Stderr, storeSaltNotice)
+	}
+}
+
+// writeRow waits for job's result and writes the row with its hash, or
+// logs why it was skipped. With -unique, a row whose hash was already
+// written is dropped silently.
+func (b *batchRun) writeRow(w *csv.Writer, job *batchJob) error {
+	cfg := b.cfg
+	res := <-job.result
+	if res.err != nil {
+		fmt.Fprintf(os.Stderr, "line %d: skipping row: %v\n", job.line, res.err)
+		b.skipped++
+		return nil
+	}
+	if !b.keep(job.record[cfg.column], res.hashed) {
+		return nil
+	}
+	value := res.hash
+	if cfg.template != nil {
+		var err error
+		if value, err = renderTemplate(cfg.template, cfg.ssn.templateData(job.record[cfg.column], res.hashed)); err != nil {
+			return err
+		}
+	}
+	row := job.record
+	if len(cfg.ssn.oldSalt) > 0 && cfg.template == nil {
+		row = append(row, res.old)
+	}
+	row = append(row, value)
+	if cfg.ssn.genSalt > 0 {
+		row = append(row, res.salt)
+	}
+	if err := w.Write(row); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	b.processed++
+	return nil
+}
+
+// reopenOutput opens an existing output file for a resumed run, truncating
+// it to offset so rows written after the last checkpoint are not repeated.
+func reopenOutput(path string, offset int64) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,171 @@
+// File: ssn_hash_batch_test.go
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// quietStderr discards the progress and summary lines the batch code
+// writes to stderr until tb finishes.
+func quietStderr(tb testing.TB) {
+	tb.Helper()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	saved := os.Stderr
+	os.Stderr = null
+	tb.Cleanup(func() {
+		os.Stderr = saved
+		null.Close()
+	})
+}
+
+// writeBatchInput writes a CSV of n rows with an SSN first column.
+func writeBatchInput(tb testing.TB, n int) string {
+	tb.Helper()
+	var sb strings.Builder
+	sb.WriteString("ssn,name\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%03d-%02d-%04d,row%d\n", 100+i%600, 1+i%99, 1+i%9999, i)
+	}
+	path := filepath.Join(tb.TempDir(), "in.csv")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// TestBatchOutputSecure checks that batch output, plain and gzipped, is
+// created 0600 and not overwritten without -force.
+func TestBatchOutputSecure(t *testing.T) {
+	quietStderr(t)
+	in := writeBatchInput(t, 3)
+	for _, name := range []string{"out.csv", "out.csv.gz"} {
+		cfg := batchConfig{
+			in:      in,
+			out:     filepath.Join(t.TempDir(), name),
+			header:  true,
+			workers: 1,
+			ssn: ssnConfig{
+				id:   idTypes["ssn"],
+				salt: []byte("s"),
+				hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding},
+			},
+		}
+		if err := runBatch(cfg); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		info, err := os.Stat(cfg.out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s: permissions %o, want 600", name, perm)
+		}
+		if err := runBatch(cfg); err == nil {
+			t.Errorf("%s: second run without -force overwrote the output", name)
+		}
+		cfg.force = true
+		if err := runBatch(cfg); err != nil {
+			t.Errorf("%s: second run with -force: %v", name, err)
+		}
+	}
+}
+
+// TestCheckpointNeedsOut checks that -checkpoint without -out is a usage
+// error, since stdout cannot be truncated back on resume.
+func TestCheckpointNeedsOut(t *testing.T) {
+	cfg := batchConfig{
+		in:         writeBatchInput(t, 1),
+		checkpoint: filepath.Join(t.TempDir(), "ckpt"),
+		ssn:        ssnConfig{id: idTypes["ssn"], salt: []byte("s")},
+	}
+	if err := runBatch(cfg); exitCode(err) != exitUsage {
+		t.Errorf("runBatch = %v (exit %d), want a usage error", err, exitCode(err))
+	}
+}
+
+// TestCheckpointResumesEarlyFailure checks that a run which fails before
+// its first -checkpoint-every rows leaves a checkpoint behind, so the rerun
+// resumes into the output it created instead of refusing to overwrite it.
+func TestCheckpointResumesEarlyFailure(t *testing.T) {
+	quietStderr(t)
+	dir := t.TempDir()
+	cfg := batchConfig{
+		in:              writeBatchInput(t, 5),
+		out:             filepath.Join(dir, "out.csv"),
+		header:          true,
+		workers:         1,
+		checkpoint:      filepath.Join(dir, "ckpt"),
+		checkpointEvery: 1000,
+		ssn: ssnConfig{
+			id:   idTypes["ssn"],
+			salt: []byte("s"),
+			hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding},
+		},
+	}
+	// The template fails on the first row, after the output is created.
+	failing := cfg
+	tmpl, err := parseOutputTemplate(`{{index .Hash 1000}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing.template = tmpl
+	if err := runBatch(failing); err == nil {
+		t.Fatal("failing run succeeded")
+	}
+	if _, err := os.Stat(cfg.checkpoint); err != nil {
+		t.Fatalf("no checkpoint after the failed run: %v", err)
+	}
+
+	if err := runBatch(cfg); err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	out, err := os.ReadFile(cfg.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(out), "\n"); lines != 6 {
+		t.Errorf("output has %d lines, want a header and 5 rows:\n%s", lines, out)
+	}
+	if !strings.HasPrefix(string(out), "ssn,name,hash_sha256\n") {
+		t.Errorf("output does not start with a single header:\n%s", out)
+	}
+}
+
+// BenchmarkBatch measures a 10,000-row run at each -workers count up to
+// the number of CPUs, to show where adding workers stops paying off.
+func BenchmarkBatch(b *testing.B) {
+	quietStderr(b)
+	in := writeBatchInput(b, 10000)
+	for workers := 1; workers <= runtime.NumCPU(); workers++ {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			dir := b.TempDir()
+			for i := 0; i < b.N; i++ {
+				cfg := batchConfig{
+					in:      in,
+					out:     filepath.Join(dir, fmt.Sprintf("out%d.csv", i)),
+					header:  true,
+					workers: workers,
+					ssn: ssnConfig{
+						id:   idTypes["ssn"],
+						salt: []byte("benchmark-salt"),
+						hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding},
+					},
+				}
+				if err := runBatch(cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// File: ssn_hash_checkpoint.go
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// defaultCheckpointEvery is how many rows pass between checkpoints unless
+// -checkpoint-every says otherwise.
+const defaultCheckpointEvery = 10000
+
+// fingerprintBytes is how much of the input file goes into its fingerprint.
+const fingerprintBytes = 4 << 10
+
+// checkpoint is the on-disk state of a resumable batch run (-checkpoint).
+type checkpoint struct {
+	// Fingerprint identifies the input: a SHA-256 over its absolute path
+	// and its first 4 KiB. A run refuses to resume from a checkpoint whose
+	// fingerprint differs.
+	Fingerprint string `json:"fingerprint"`
+	// Line is the CSV line number of the last row fully handled (written or
+	// skipped). Rows starting at or before it are skipped on resume.
+	Line int `json:"line"`
+	// OutOffset is the size of the output file when the checkpoint was
+	// taken. On resume the output is truncated back to it, dropping any
+	// rows written after the last checkpoint so none is duplicated.
+	OutOffset int64 `json:"out_offset"`
+}
+
+// inputFingerprint computes the Fingerprint for the input file at path.
+func inputFingerprint(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening input: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	h.Write([]byte(abs))
+	h.Write([]byte{0})
+	if _, err := io.CopyN(h, f, fingerprintBytes); err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadCheckpoint reads the checkpoint at path, returning nil if none exists.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// save writes c to path durably: the data goes to a temporary file that is
+// fsynced and then renamed over path, so a crash leaves either the old or
+// the new checkpoint, never a torn one.
+func (c checkpoint) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointer takes a checkpoint every `every` rows of a batch run.
+type checkpointer struct {
+	path    string
+	every   int
+	state   checkpoint
+	pending int
+}
+
+// advance records that the row starting at line has been handled, and
+// takes a checkpoint once enough rows have accumulated.
+func (c *checkpointer) advance(line int, w *csv.Writer, out *os.File) error {
+	c.state.Line = line
+	c.pending++
+	if c.pending < c.every {
+		return nil
+	}
+	return c.flush(w, out)
+}
+
+// flush makes every row written so far durable, then saves the
+// checkpoint. The output is synced first so the checkpoint never points
+// past data that could still be lost.
+func (c *checkpointer) flush(w *csv.Writer, out *os.File) error {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if out != nil {
+		if err := out.Sync(); err != nil {
+			return fmt.Errorf("syncing output: %w", err)
+		}
+		off, err := out.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("syncing output: %w", err)
+		}
+		c.state.OutOffset = off
+	}
+	c.pending = 0
+	return c.state.save(c.path)
+}
@@ -0,0 +1,27 @@
+// File: ssn_hash_constwork.go
+
+package main
+
+import "github.com/IDObjects/main/ssnhash"
+
+// constantWorkPlaceholder is hashed in place of an identifier that
+// -constant-work rejected. It only needs to cost what a real nine-digit
+// identifier costs.
+const constantWorkPlaceholder = "000000000"
+
+// spendHashWork runs the hashing a valid identifier would get (fresh salt,
+// old salt and KDF included) on the placeholder and discards the result.
+// It is called when -constant-work rejects an input, so that an invalid SSN
+// is not answered measurably faster than a valid one. Nothing is audited.
+func (c ssnConfig) spendHashWork() {
+	c.audit = nil
+	c.hashPrepared([]byte(constantWorkPlaceholder))
+}
+
+// spendVerifyWork is spendHashWork for -verify: it also decodes and
+// compares against expected, as a real check would.
+func (c ssnConfig) spendVerifyWork(expected string) {
+	if sum, err := c.hash.Digest([]byte(constantWorkPlaceholder), c.salt); err == nil {
+		ssnhash.VerifyDigest(sum, expected, c.hash.Encoding)
+	}
+}
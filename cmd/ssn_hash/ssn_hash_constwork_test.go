@@ -0,0 +1,43 @@
+// File: ssn_hash_constwork_test.go
+
+package main
+
+import (
+	"testing"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// TestConstantWorkTiming benchmarks process on an invalid and a valid SSN
+// and checks that -constant-work keeps their costs within a factor of two,
+// while without it the invalid input is answered far faster. 1,000 rounds
+// make hashing dominate, so scheduler noise stays well inside the band.
+func TestConstantWorkTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	sc := ssnConfig{
+		id:   idTypes["ssn"],
+		salt: []byte("s"),
+		hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding, Rounds: 1000},
+	}
+	ratio := func(c ssnConfig) float64 {
+		cost := func(ssn string) float64 {
+			r := testing.Benchmark(func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					c.process(ssn)
+				}
+			})
+			return float64(r.NsPerOp())
+		}
+		return cost("12345") / cost("123-45-6789")
+	}
+
+	if r := ratio(sc); r > 0.5 {
+		t.Errorf("without -constant-work, invalid/valid cost ratio = %.2f, want well under 1", r)
+	}
+	sc.constantWork = true
+	if r := ratio(sc); r < 0.5 || r > 2 {
+		t.Errorf("with -constant-work, invalid/valid cost ratio = %.2f, want between 0.5 and 2", r)
+	}
+}
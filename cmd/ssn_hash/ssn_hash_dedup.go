@@ -0,0 +1,81 @@
+// File: ssn_hash_dedup.go
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// dedupTracker counts repeated identifiers during a batch run
+// (-dedup-report). It keys on the SHA-256 of each normalized identifier
+// rather than the identifier itself, so memory stays at a fixed 32 bytes per
+// distinct value and no plaintext is retained.
+type dedupTracker struct {
+	seen       map[[32]byte]int
+	keepMasked bool
+	masked     map[[32]byte]string // masked form of each duplicated value, with keepMasked
+	order      [][32]byte          // duplicated values in first-repeat order
+	duplicates int                 // rows that repeated an earlier value
+}
+
+func newDedupTracker(keepMasked bool) *dedupTracker {
+	d := &dedupTracker{seen: map[[32]byte]int{}, keepMasked: keepMasked}
+	if keepMasked {
+		d.masked = map[[32]byte]string{}
+	}
+	return d
+}
+
+// add records one occurrence of a normalized identifier. masked is only
+// kept if the value turns out to be a duplicate.
+func (d *dedupTracker) add(normalized, masked string) {
+	key := sha256.Sum256([]byte(normalized))
+	d.seen[key]++
+	if d.seen[key] == 2 {
+		d.order = append(d.order, key)
+		if d.keepMasked {
+			d.masked[key] = masked
+		}
+	}
+	if d.seen[key] > 1 {
+		d.duplicates++
+	}
+}
+
+// report writes the duplicate summary to w.
+func (d *dedupTracker) report(w io.Writer) {
+	fmt.Fprintf(w, "Duplicates: %d rows repeated an earlier value (%d distinct values seen more than once)\n", d.duplicates, len(d.order))
+	if !d.keepMasked {
+		return
+	}
+	for _, key := range d.order {
+		fmt.Fprintf(w, "  %s seen %d times\n", d.masked[key], d.seen[key])
+	}
+}
+
+// uniqueFilter suppresses batch rows whose hash has already been written
+// (-unique). Like dedupTracker it keeps only a SHA-256 of each distinct
+// hash, so the set costs the same 32 bytes (plus map overhead) per entry
+// whatever the algorithm and encoding.
+type uniqueFilter struct {
+	seen       map[[32]byte]struct{}
+	suppressed int // rows dropped because their hash was already written
+}
+
+func newUniqueFilter() *uniqueFilter {
+	return &uniqueFilter{seen: map[[32]byte]struct{}{}}
+}
+
+// keep reports whether hash is new, remembering it if so, and counts the
+// row as suppressed otherwise.
+func (u *uniqueFilter) keep(hash string) bool {
+	key := sha256.Sum256([]byte(hash))
+	if _, ok := u.seen[key]; ok {
+		u.suppressed++
+		return false
+	}
+	u.seen[key] = struct{}{}
+	return true
+}
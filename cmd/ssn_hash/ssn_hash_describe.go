@@ -0,0 +1,89 @@
+// File: ssn_hash_describe.go
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// hiddenFlags are registered like any other flag but left out of -help.
+var hiddenFlags = map[string]bool{"describe-flags": true}
+
+// flagDescription is one entry of -describe-flags=json.
+type flagDescription struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+// flagType names the kind of value f takes: bool, int, uint, string, ...,
+// "repeated string" for flags that may be given several times, or "value"
+// for anything else.
+func flagType(f *flag.Flag) string {
+	switch f.Value.(type) {
+	case *stringList, *fieldList:
+		return "repeated string"
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return "bool"
+	}
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+	switch g.Get().(type) {
+	case int:
+		return "int"
+	case int64:
+		return "int64"
+	case uint:
+		return "uint"
+	case uint64:
+		return "uint64"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case time.Duration:
+		return "duration"
+	}
+	return "value"
+}
+
+// describeFlags writes every flag registered in fs, hidden ones included,
+// to w as a JSON array in name order. Defaults come from DefValue, so the
+// listing is the same whatever else is on the command line.
+func describeFlags(w io.Writer, fs *flag.FlagSet, format string) error {
+	if format != "json" {
+		return usageErrorf("unsupported -describe-flags format %q (want json)", format)
+	}
+	flags := []flagDescription{}
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, flagDescription{Name: f.Name, Type: flagType(f), Default: f.DefValue, Usage: f.Usage})
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(flags); err != nil {
+		return fmt.Errorf("writing flag list: %w", err)
+	}
+	return nil
+}
+
+// printVisibleDefaults is flag.PrintDefaults without the hidden flags.
+func printVisibleDefaults(out io.Writer) {
+	visible := flag.NewFlagSet(flag.CommandLine.Name(), flag.ContinueOnError)
+	visible.SetOutput(out)
+	flag.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+			// Var takes the current value as the default; keep the real one.
+			visible.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+	visible.PrintDefaults()
+}
@@ -0,0 +1,100 @@
+// File: ssn_hash_exit.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// Exit codes. main derives one from the error run returns, so every
+// failure path maps to exactly one of these.
+const (
+	exitOK         = 0
+	exitFailure    = 1 // anything not covered below, including a -verify mismatch
+	exitUsage      = 2 // bad or conflicting flags, or missing required ones
+	exitValidation = 3 // an SSN or EIN failed normalization or -validate
+	exitIO         = 4 // a file, terminal or network operation failed
+)
+
+// exitCodesHelp is appended to the -help output.
+const exitCodesHelp = `
+Exit codes:
+  0  success
+  1  error, or -verify/-verify-in found a mismatch
+  2  usage error: bad, conflicting or missing flags
+  3  validation failure: an SSN or EIN did not normalize or validate
+  4  I/O error: a file, terminal or network operation failed
+`
+
+// codedError attaches an exit code to err.
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// usageErrorf reports a bad or conflicting flag.
+func usageErrorf(format string, args ...any) error {
+	return &codedError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// usageError marks err, typically an invalid flag value, as a usage error.
+func usageError(err error) error {
+	return &codedError{code: exitUsage, err: err}
+}
+
+// usage is returned when required flags are missing. main prints it as is,
+// without the "Error:" prefix.
+type usage string
+
+func (u usage) Error() string { return string(u) }
+
+// errReported stands for failures that have already been reported, such as
+// the per-SSN errors of a multi-SSN run; main only sets the exit code.
+var errReported = errors.New("failure already reported")
+
+// reported returns an error that exits with code without printing anything.
+func reported(code int) error {
+	return &codedError{code: code, err: errReported}
+}
+
+// exitCode maps an error returned by run to the process exit code.
+func exitCode(err error) int {
+	var coded *codedError
+	var invalid *ssnhash.ValidationError
+	var pathErr *fs.PathError
+	var netErr *net.OpError
+	var u usage
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.As(err, &u):
+		return exitUsage
+	case errors.As(err, &invalid):
+		return exitValidation
+	case errors.As(err, &pathErr), errors.As(err, &netErr):
+		return exitIO
+	}
+	return exitFailure
+}
+
+// setUsage makes -help list the exit codes after the flags.
+func setUsage() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		printVisibleDefaults(out)
+		fmt.Fprint(out, exitCodesHelp)
+	}
+}
@@ -0,0 +1,71 @@
+// File: ssn_hash_exit_test.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/term"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// TestExitCode checks that every documented exit code category is reached,
+// including through wrapping.
+func TestExitCode(t *testing.T) {
+	_, invalid := ssnhash.NormalizeSSN("12345")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"plain error", errors.New("boom"), exitFailure},
+		{"mismatch", reported(exitFailure), exitFailure},
+		{"usageErrorf", usageErrorf("-x is bad"), exitUsage},
+		{"usageError", usageError(errors.New("bad value")), exitUsage},
+		{"usage", usage("Usage: ssn_hash ..."), exitUsage},
+		{"validation", invalid, exitValidation},
+		{"wrapped validation", fmt.Errorf("line 3: %w", invalid), exitValidation},
+		{"path error", fmt.Errorf("opening input: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}), exitIO},
+		{"network error", &net.OpError{Op: "listen", Err: errors.New("address in use")}, exitIO},
+		{"coded I/O", &codedError{code: exitIO, err: errors.New("no terminal")}, exitIO},
+		{"reported validation", reported(exitValidation), exitValidation},
+	}
+	for _, tc := range tests {
+		if got := exitCode(tc.err); got != tc.want {
+			t.Errorf("%s: exitCode(%v) = %d, want %d", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
+
+// TestExitCodePaths checks the exit codes of failures raised outside flag
+// parsing.
+func TestExitCodePaths(t *testing.T) {
+	sc := ssnConfig{id: idTypes["ssn"], hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding}}
+	if got := exitCode(runBench(io.Discard, -1, sc)); got != exitUsage {
+		t.Errorf("-bench=-1: exit %d, want %d", got, exitUsage)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createSecureFile(path, false); exitCode(err) != exitUsage {
+		t.Errorf("existing -out without -force: exit %d (%v), want %d", exitCode(err), err, exitUsage)
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal")
+	}
+	if _, err := readHidden(""); exitCode(err) != exitIO {
+		t.Errorf("-interactive without a terminal: exit %d (%v), want %d", exitCode(err), err, exitIO)
+	}
+}
@@ -0,0 +1,77 @@
+// File: ssn_hash_fields.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// fieldList is a flag.Value collecting repeated -field=name:value pairs in
+// the order given.
+type fieldList []ssnhash.Field
+
+func (l *fieldList) String() string {
+	names := make([]string, len(*l))
+	for i, f := range *l {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *fieldList) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	if !ok || name == "" {
+		return errors.New("want name:value with a non-empty name")
+	}
+	for _, f := range *l {
+		if f.Name == name {
+			return fmt.Errorf("field %q given twice", name)
+		}
+	}
+	*l = append(*l, ssnhash.Field{Name: name, Value: value})
+	return nil
+}
+
+// fieldsLabel describes the construction for text output, e.g.
+// `SHA-256(tag|salt|ssn|dob)`.
+func fieldsLabel(fields []ssnhash.Field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return "SHA-256(tag|salt|" + strings.Join(names, "|") + ")"
+}
+
+// runFields hashes fields under tag and the configured salt with
+// HashFields and writes one text line to w. A field named after the idtype
+// is prepared like -ssn first. The hash is audited before it is written.
+func runFields(w io.Writer, tag string, fields []ssnhash.Field, sc ssnConfig) error {
+	var id []byte
+	for i, f := range fields {
+		if f.Name == sc.id.name {
+			v, err := prepareID(f.Value, sc.id, sc.raw, sc.validate)
+			if err != nil {
+				return err
+			}
+			fields[i].Value = v
+			id = []byte(v)
+		}
+	}
+	defer ssnhash.Zeroize(id)
+
+	h := hashed{hash: ssnhash.HashFields(tag, fields, string(sc.salt))}
+	if sc.audit != nil {
+		if err := sc.audit.record(sc, h, id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s = %s\n", fieldsLabel(fields), h.hash); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
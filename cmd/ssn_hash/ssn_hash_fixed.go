@@ -0,0 +1,155 @@
+// File: ssn_hash_fixed.go
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fixedField locates the SSN in each line of a fixed-width input
+// (-format=fixed): it occupies len bytes starting at the zero-based byte
+// offset start.
+type fixedField struct {
+	start, len int
+}
+
+// extract returns the field's bytes of line, or an error if the line ends
+// before the field does, so a short line is skipped rather than sliced out
+// of range. The bounds are compared without adding start and len, which
+// could overflow for huge flag values.
+func (f fixedField) extract(line string) (string, error) {
+	if f.start > len(line) || f.len > len(line)-f.start {
+		return "", fmt.Errorf("%d-byte line that ends before the SSN (%d bytes at offset %d)", len(line), f.len, f.start)
+	}
+	return line[f.start : f.start+f.len], nil
+}
+
+// runFixed is runBatch for fixed-width input: it hashes the field at
+// *cfg.fixed in every line of cfg.in and writes the line with the hash
+// appended after a space, or with cfg.hashOnly the hash alone. Short or
+// invalid lines are logged to stderr and skipped; with cfg.hashOnly a
+// "SKIPPED ..." line takes their place, so the output stays aligned with
+// the input as in -stdin mode. Lines are hashed in order on a single
+// goroutine; -workers does not apply.
+func runFixed(cfg batchConfig) error {
+	if cfg.dryRun || cfg.checkpoint != "" {
+		return usageErrorf("-format=fixed does not support -dry-run or -checkpoint")
+	}
+
+	inFile, err := openMaybeGzip(cfg.in, false)
+	if err != nil {
+		return fmt.Errorf("opening input: %w", err)
+	}
+	defer inFile.Close()
+
+	batch := newBatchRun(cfg)
+	dst, err := batch.createOutput()
+	if err != nil {
+		return err
+	}
+	defer batch.closeOutput()
+	w := bufio.NewWriter(dst)
+
+	// bufio.Reader rather than Scanner, so long records have no size limit.
+	r := bufio.NewReader(inFile)
+	for lineNo := 1; ; lineNo++ {
+		line, err := r.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if lineNo == 1 && cfg.header {
+			if !cfg.hashOnly {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+			}
+			continue
+		}
+
+		id, err := cfg.fixed.extract(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: skipping %v\n", lineNo, err)
+			if err := skipFixedLine(w, batch, lineNo, err); err != nil {
+				return err
+			}
+			continue
+		}
+		h, err := cfg.ssn.process(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: skipping line: %v\n", lineNo, err)
+			if err := skipFixedLine(w, batch, lineNo, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if !batch.keep(id, h) {
+			continue
+		}
+		out, err := fixedOutput(cfg, line, id, h)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, out); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		batch.processed++
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := batch.closeOutput(); err != nil {
+		return err
+	}
+	batch.report("lines")
+	return nil
+}
+
+// skipFixedLine counts a line that was not hashed and, with -hash-only,
+// writes the placeholder that keeps the output aligned with the input.
+func skipFixedLine(w io.Writer, batch *batchRun, lineNo int, reason error) error {
+	batch.skipped++
+	if !batch.cfg.hashOnly {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "%s line %d: %v\n", skipPrefix, lineNo, reason); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// fixedOutput builds the output for one line whose field id hashed to h:
+// the input line (unless hashOnly), the old hash during a -salt-old
+// rotation, the hash or rendered template, and any generated salt,
+// separated by spaces.
+func fixedOutput(cfg batchConfig, line, id string, h hashed) (string, error) {
+	var cols []string
+	if !cfg.hashOnly {
+		cols = append(cols, line)
+	}
+	if len(cfg.ssn.oldSalt) > 0 && cfg.template == nil {
+		cols = append(cols, h.old)
+	}
+	value := h.hash
+	if cfg.template != nil {
+		var err error
+		if value, err = renderTemplate(cfg.template, cfg.ssn.templateData(id, h)); err != nil {
+			return "", err
+		}
+	}
+	cols = append(cols, value)
+	if h.salt != "" {
+		cols = append(cols, h.salt)
+	}
+	return strings.Join(cols, " "), nil
+}
@@ -0,0 +1,85 @@
+// File: ssn_hash_fixed_test.go
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// TestFixedHashOnlyAligned checks that with -hash-only every input line,
+// short or invalid ones included, yields exactly one output line.
+func TestFixedHashOnlyAligned(t *testing.T) {
+	quietStderr(t)
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	input := "AB123456789\nAB12\nAB000000000\nAB234567890\n"
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := batchConfig{
+		in:       in,
+		out:      filepath.Join(dir, "out.txt"),
+		fixed:    &fixedField{start: 2, len: 9},
+		hashOnly: true,
+		ssn: ssnConfig{
+			id:       idTypes["ssn"],
+			salt:     []byte("s"),
+			validate: true,
+			hash:     ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding},
+		},
+	}
+	if err := runBatch(cfg); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(cfg.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	want := []string{
+		ssnhash.HashSSN("123456789", "s"),
+		skipPrefix + " line 2:",
+		skipPrefix + " line 3:",
+		ssnhash.HashSSN("234567890", "s"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d output lines for %d input lines:\n%s", len(got), len(want), out)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("line %d = %q, want prefix %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+// TestFixedExtractBounds checks that fields past the end of the line, even
+// at offsets where start+len overflows, are errors rather than panics.
+func TestFixedExtractBounds(t *testing.T) {
+	tests := []struct {
+		f    fixedField
+		line string
+		want string // "" means an error
+	}{
+		{fixedField{start: 2, len: 3}, "AB123", "123"},
+		{fixedField{start: 2, len: 4}, "AB123", ""},
+		{fixedField{start: 6, len: 1}, "AB123", ""},
+		{fixedField{start: math.MaxInt, len: 1}, "AB123", ""},
+		{fixedField{start: 1, len: math.MaxInt}, "AB123", ""},
+	}
+	for _, tc := range tests {
+		got, err := tc.f.extract(tc.line)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%+v.extract(%q) = %q, want an error", tc.f, tc.line, got)
+			}
+		} else if err != nil || got != tc.want {
+			t.Errorf("%+v.extract(%q) = %q, %v, want %q", tc.f, tc.line, got, err, tc.want)
+		}
+	}
+}
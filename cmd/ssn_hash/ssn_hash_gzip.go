@@ -0,0 +1,85 @@
+// File: ssn_hash_gzip.go
+
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"strings"
+)
+
+// isGzipPath reports whether path names a gzip file (ends in ".gz").
+func isGzipPath(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
+
+// gzipFile is a batch input or output file that is transparently
+// (de)compressed when its name ends in ".gz", so plaintext never has to be
+// staged on disk.
+type gzipFile struct {
+	f      *os.File
+	zr     *gzip.Reader // set for compressed input
+	zw     *gzip.Writer // set for compressed output
+	closed bool
+}
+
+// openMaybeGzip opens path for reading, or creates it for writing when
+// write is set, wrapping it in gzip when the name ends in ".gz". Output is
+// created by createSecureFile: 0600, and an existing file is refused.
+func openMaybeGzip(path string, write bool) (*gzipFile, error) {
+	var f *os.File
+	var err error
+	if write {
+		f, err = createSecureFile(path, false)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	g := &gzipFile{f: f}
+	if !isGzipPath(path) {
+		return g, nil
+	}
+	if write {
+		g.zw = gzip.NewWriter(f)
+	} else if g.zr, err = gzip.NewReader(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *gzipFile) Read(p []byte) (int, error) {
+	if g.zr != nil {
+		return g.zr.Read(p)
+	}
+	return g.f.Read(p)
+}
+
+func (g *gzipFile) Write(p []byte) (int, error) {
+	if g.zw != nil {
+		return g.zw.Write(p)
+	}
+	return g.f.Write(p)
+}
+
+// Close finishes the gzip stream, if any, and closes the file. Only the
+// first call does anything, so Close may also be deferred.
+func (g *gzipFile) Close() error {
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+	var err error
+	if g.zw != nil {
+		err = g.zw.Close()
+	}
+	if g.zr != nil {
+		g.zr.Close()
+	}
+	if cerr := g.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
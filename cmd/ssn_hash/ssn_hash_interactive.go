@@ -0,0 +1,70 @@
+// File: ssn_hash_interactive.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"golang.org/x/term"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// readHidden prints prompt to stderr and reads one line from the terminal
+// on stdin with echo disabled. It refuses to read from anything other than
+// a terminal, where the input would not be hidden. The caller must zeroize
+// the result.
+func readHidden(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, &codedError{code: exitIO, err: errors.New("-interactive needs a terminal on stdin (use -stdin to pipe SSNs in)")}
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, &codedError{code: exitIO, err: fmt.Errorf("reading from terminal: %w", err)}
+	}
+	return b, nil
+}
+
+// runInteractive prompts for one SSN and writes its hash to w. The typed
+// SSN and its normalized form stay in byte slices that are zeroized once
+// the hash is computed. -validate and -show-masked/-template still need a
+// string copy, which cannot be wiped (see ssnhash.Zeroize).
+func runInteractive(w io.Writer, sc ssnConfig, format string, tmpl *template.Template, showMasked bool) error {
+	buf, err := readHidden(strings.ToUpper(sc.id.name) + ": ")
+	if err != nil {
+		return err
+	}
+	defer ssnhash.Zeroize(buf)
+
+	if sc.validate {
+		if err := sc.id.validate(string(buf)); err != nil {
+			return fmt.Errorf("invalid %s: %w", strings.ToUpper(sc.id.name), err)
+		}
+	}
+	input := buf
+	if !sc.raw {
+		// The byte form of normalization avoids a string copy here.
+		if input, err = sc.id.normalizeBytes(buf); err != nil {
+			return err
+		}
+		defer ssnhash.Zeroize(input)
+	}
+
+	h, err := sc.hashPrepared(input)
+	if err != nil {
+		return err
+	}
+	var id string
+	if showMasked || tmpl != nil {
+		id = string(input)
+	}
+	return printHashed(w, id, 0, sc, format, tmpl, showMasked, h)
+}
@@ -0,0 +1,113 @@
+// File: ssn_hash_output.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// Result is the machine-readable output of a single hashing run
+// (-format=json). It deliberately has no field for the SSN or the salt so
+// that neither can end up in downstream logs.
+type Result struct {
+	// IDType is the -idtype of the hashed identifier, "ssn" or "ein", so
+	// the two are never conflated downstream.
+	IDType string `json:"idtype"`
+	// Algo names the construction that produced Hash: "sha256",
+	// "hmac_sha256", "argon2id", "pbkdf2_sha256", ...
+	Algo string `json:"algo"`
+	// Encoding is the -encoding applied to the digest ("hex", "base64",
+	// ...); empty for argon2id and pbkdf2, whose PHC strings have their
+	// own format.
+	Encoding string `json:"encoding,omitempty"`
+	// Rounds is the -rounds count when greater than one.
+	Rounds int `json:"rounds,omitempty"`
+	// Truncate is the -truncate length in bytes of a shortened digest.
+	Truncate int `json:"truncate,omitempty"`
+	// Hash is the encoded digest, or a PHC string for argon2id and pbkdf2.
+	Hash string `json:"hash"`
+	// Normalized reports whether the SSN was normalized before hashing
+	// (false with -raw).
+	Normalized bool `json:"normalized"`
+	// GeneratedSalt is the hex-encoded per-record salt drawn by -gen-salt.
+	// It must be stored with Hash, which cannot be verified without it. The
+	// configured -salt is never part of the output.
+	GeneratedSalt string `json:"generated_salt,omitempty"`
+	// SaltVersion is "old" or "new" during a -salt-old rotation, when each
+	// SSN yields one Result per salt.
+	SaltVersion string `json:"salt_version,omitempty"`
+	// Index is the 1-based position of the SSN among several -ssn flags,
+	// so results can be matched to inputs when one of them fails.
+	Index int `json:"index,omitempty"`
+}
+
+// storeSaltNotice accompanies every output that contains a generated salt.
+const storeSaltNotice = "Store the generated salt with the hash: it is required to verify the hash later and cannot be recovered."
+
+// writeResult prints r to w in the given -format: "text" keeps the
+// historical "SHA-256(salt+ssn) = <hex>" line, with every line starting with
+// prefix, and "json" emits one JSON object.
+func writeResult(w io.Writer, format, prefix, label string, r Result) error {
+	switch format {
+	case "text":
+		if _, err := fmt.Fprintf(w, "%s%s = %s\n", prefix, label, r.Hash); err != nil {
+			return err
+		}
+		if r.GeneratedSalt != "" {
+			_, err := fmt.Fprintf(w, "%sgenerated salt (hex) = %s\n%s%s\n", prefix, r.GeneratedSalt, prefix, storeSaltNotice)
+			return err
+		}
+		return nil
+	case "json":
+		return json.NewEncoder(w).Encode(r)
+	}
+	return fmt.Errorf("unsupported format %q (want text or json)", format)
+}
+
+// createSecureFile creates path with 0600 permissions, since even a hash of
+// an SSN is sensitive in some threat models. An existing file is refused
+// unless force is set, in which case it is truncated and its permissions
+// are tightened to 0600.
+func createSecureFile(path string, force bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, usageErrorf("output file %s already exists (use -force to overwrite)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating output file: %w", err)
+	}
+	if force {
+		if err := f.Chmod(0o600); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("setting output file permissions: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// withOutput runs fn with the destination chosen by -out: a file created by
+// createSecureFile when path is set, otherwise stdout. An error closing the
+// file is returned if fn itself succeeded.
+func withOutput(path string, force bool, fn func(io.Writer) error) error {
+	if path == "" {
+		return fn(os.Stdout)
+	}
+	f, err := createSecureFile(path, force)
+	if err != nil {
+		return err
+	}
+	err = fn(f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("writing output file: %w", cerr)
+	}
+	return err
+}
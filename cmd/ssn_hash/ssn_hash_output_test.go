@@ -0,0 +1,38 @@
+// File: ssn_hash_output_test.go
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// TestPrintHashIndex checks that JSON results carry the -ssn position
+// given to printHash, and omit it for a lone SSN.
+func TestPrintHashIndex(t *testing.T) {
+	sc := ssnConfig{
+		id:   idTypes["ssn"],
+		salt: []byte("s"),
+		hash: ssnhash.Config{Algo: ssnhash.DefaultAlgo, Encoding: ssnhash.DefaultEncoding, Rounds: 1},
+	}
+	for _, index := range []int{0, 3} {
+		var buf bytes.Buffer
+		if err := printHash(&buf, "123456789", index, sc, "json", nil, false); err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+			t.Fatalf("bad JSON %q: %v", buf.String(), err)
+		}
+		got, ok := fields["index"]
+		switch {
+		case index == 0 && ok:
+			t.Errorf("lone SSN: index = %v, want it omitted", got)
+		case index > 0 && got != float64(index):
+			t.Errorf("index = %v, want %d", got, index)
+		}
+	}
+}
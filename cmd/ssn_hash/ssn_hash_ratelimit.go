@@ -0,0 +1,100 @@
+// File: ssn_hash_ratelimit.go
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRateClients bounds how many client IPs the limiter tracks, so a flood
+// of distinct addresses cannot exhaust memory. At about 50 bytes per
+// entry the table stays well under a megabyte.
+const maxRateClients = 10000
+
+// bucket is one client's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter allows each client IP rate requests per second, with bursts
+// of up to rate requests. It keys on the connection's remote address only:
+// X-Forwarded-For is client-controlled and would let an attacker pick a
+// fresh identity per request.
+type rateLimiter struct {
+	rate float64
+
+	mu        sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(rate int) *rateLimiter {
+	return &rateLimiter{rate: float64(rate), clients: make(map[string]*bucket)}
+}
+
+// allow reports whether the client at ip may make a request now, spending
+// a token if so.
+func (l *rateLimiter) allow(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.clients[ip]
+	if !ok {
+		if len(l.clients) >= maxRateClients && !l.sweep(now) {
+			// Every tracked client is active. Refusing the newcomer keeps
+			// memory bounded without resetting anyone's limit.
+			return false
+		}
+		b = &bucket{tokens: l.rate, last: now}
+		l.clients[ip] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.rate {
+		b.tokens = l.rate
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep drops clients whose buckets have refilled completely; forgetting
+// them changes nothing, since a new client starts with a full bucket. It
+// runs at most once per second, so a full table cannot turn every request
+// into a scan. It reports whether room was made.
+func (l *rateLimiter) sweep(now time.Time) bool {
+	if now.Sub(l.lastSweep) < time.Second {
+		return false
+	}
+	l.lastSweep = now
+	for ip, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.rate {
+			delete(l.clients, ip)
+		}
+	}
+	return len(l.clients) < maxRateClients
+}
+
+// limit wraps next, answering 429 Too Many Requests to clients over their
+// rate.
+func (l *rateLimiter) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		if !l.allow(ip, time.Now()) {
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,98 @@
+// File: ssn_hash_salt.go
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// saltEnvVar names the environment variable consulted when neither -salt nor
+// -salt-file is given.
+const saltEnvVar = "SSN_HASH_SALT"
+
+// pepperEnvVar names the environment variable consulted when -pepper is not
+// given.
+const pepperEnvVar = "PEPPER"
+
+// resolvePepper returns the application-wide pepper from -pepper or, failing
+// that, the PEPPER environment variable, warning on stderr if both are set.
+// The pepper is a secret and must never be echoed in any output.
+func resolvePepper(flagPepper string) string {
+	envPepper := os.Getenv(pepperEnvVar)
+	if flagPepper != "" && envPepper != "" {
+		fmt.Fprintf(os.Stderr, "Warning: pepper set by -pepper, %s; using -pepper\n", pepperEnvVar)
+	}
+	if flagPepper != "" {
+		return flagPepper
+	}
+	return envPepper
+}
+
+// resolveSalt picks the salt from, in order of precedence, the -salt flag,
+// the -salt-file file and the SSN_HASH_SALT environment variable. When more
+// than one source is set a warning naming the winner is printed to stderr.
+func resolveSalt(flagSalt, saltFile string) (string, error) {
+	envSalt := os.Getenv(saltEnvVar)
+
+	var sources []string
+	if flagSalt != "" {
+		sources = append(sources, "-salt")
+	}
+	if saltFile != "" {
+		sources = append(sources, "-salt-file")
+	}
+	if envSalt != "" {
+		sources = append(sources, saltEnvVar)
+	}
+	if len(sources) > 1 {
+		fmt.Fprintf(os.Stderr, "Warning: salt set by %s; using %s\n", strings.Join(sources, ", "), sources[0])
+	}
+
+	switch {
+	case flagSalt != "":
+		return flagSalt, nil
+	case saltFile != "":
+		return readSaltFile(saltFile)
+	}
+	return envSalt, nil
+}
+
+// readSaltFile returns the contents of path with a single trailing newline
+// removed. Any other whitespace is kept, since it may be part of the salt.
+func readSaltFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading salt file: %w", err)
+	}
+	s := string(data)
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2], nil
+	}
+	return strings.TrimSuffix(s, "\n"), nil
+}
+
+// blankSalt reports whether salt, after any -normalize-salt, is empty or
+// whitespace only. Such a salt adds nothing: every hash can be reversed by
+// hashing all billion possible SSNs.
+func blankSalt(salt string) bool {
+	return strings.TrimSpace(salt) == ""
+}
+
+// checkSalt guards against a salt that was supplied but is blank, typically
+// a misquoted shell variable such as -salt="$SALT" with SALT unset or " ".
+// name identifies the salt in the message. It warns on stderr, or with
+// strict returns an error. The salt itself is never changed.
+func checkSalt(name, salt string, strict bool) error {
+	if !blankSalt(salt) {
+		return nil
+	}
+	if strict {
+		return usageErrorf("%s is empty or whitespace only; refusing to produce effectively unsalted hashes", name)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s is empty or whitespace only. The hashes are effectively unsalted "+
+		"and anyone can recover every SSN by hashing all 10^9 candidates. Check how the salt is quoted "+
+		"and passed; use -strict to make this an error.\n", name)
+	return nil
+}
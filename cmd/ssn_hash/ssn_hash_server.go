@@ -0,0 +1,131 @@
+// File: ssn_hash_server.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// maxRequestBytes caps the size of a /hash request body.
+const maxRequestBytes = 4 << 10
+
+// Server timeouts. The read timeouts stop slowloris clients from holding
+// connections open by trickling in headers or a body.
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 10 * time.Second
+	writeTimeout      = 30 * time.Second // allows for Argon2id stretching
+	idleTimeout       = 60 * time.Second
+	maxHeaderBytes    = 8 << 10
+)
+
+// defaultRate is the -rate default: requests per second per client IP.
+const defaultRate = 10
+
+// hashRequest is the body of POST /hash.
+type hashRequest struct {
+	SSN  string `json:"ssn"`
+	Salt string `json:"salt"`
+}
+
+// hashResponse is returned by POST /hash on success.
+type hashResponse struct {
+	Hash string `json:"hash"`
+}
+
+// errorResponse is returned with every non-2xx status.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newHashHandler serves POST /hash using the same normalization, validation
+// and hashing settings as the CLI, with the salt taken from each request.
+// With default flags the result is exactly HashSSN(ssn, salt). Request
+// bodies are never logged.
+func newHashHandler(cfg ssnConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hash", func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+
+		var req hashRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "request body too large"})
+				return
+			}
+			// The decoder's message can quote parts of the body, so it is
+			// not passed back.
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: `malformed JSON body, expected {"ssn":"...","salt":"..."}`})
+			return
+		}
+		switch {
+		case req.SSN == "":
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "missing field: ssn"})
+			return
+		case req.Salt == "" || (cfg.normSalt && ssnhash.NormalizeSalt(req.Salt) == ""):
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "missing field: salt"})
+			return
+		}
+
+		if cfg.normSalt {
+			req.Salt = ssnhash.NormalizeSalt(req.Salt)
+		}
+		if blankSalt(req.Salt) {
+			if cfg.strict {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "salt must not be whitespace only"})
+				return
+			}
+			log.Printf("warning: request with a whitespace-only salt from %s; its hash is effectively unsalted", r.RemoteAddr)
+		}
+		salt := []byte(req.Salt)
+		defer ssnhash.Zeroize(salt)
+		reqCfg := cfg
+		reqCfg.salt = salt
+
+		h, err := reqCfg.process(req.SSN)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, hashResponse{Hash: h.hash})
+	})
+	return mux
+}
+
+// writeJSON sends v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// runServer listens on addr and serves the hashing API until it fails. With
+// a salt in hand, the endpoint is an oracle for brute-forcing the small SSN
+// space, so each client IP is limited to rate requests per second (0
+// disables the limit).
+func runServer(addr string, cfg ssnConfig, rate int) error {
+	handler := newHashHandler(cfg)
+	if rate > 0 {
+		handler = newRateLimiter(rate).limit(handler)
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+	log.Printf("listening on %s", addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,60 @@
+// File: ssn_hash_stream.go
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// skipPrefix marks an output line that stands in for an input line which
+// was not hashed, so the output stays aligned with the input.
+const skipPrefix = "SKIPPED"
+
+// runStream reads one SSN per line from r and writes one hash per line to w,
+// in the same order. Blank or invalid lines produce a "SKIPPED ..." line
+// instead of a hash, and a total/ok/skipped summary goes to stderr.
+func runStream(r io.Reader, w io.Writer, cfg ssnConfig) error {
+	scanner := bufio.NewScanner(r)
+	out := bufio.NewWriter(w)
+
+	var total, ok, skipped int
+	for scanner.Scan() {
+		total++
+		line := scanner.Text()
+
+		var result string
+		if strings.TrimSpace(line) == "" {
+			result = fmt.Sprintf("%s line %d: blank line", skipPrefix, total)
+			skipped++
+		} else if h, err := cfg.process(line); err != nil {
+			result = fmt.Sprintf("%s line %d: %v", skipPrefix, total, err)
+			skipped++
+		} else {
+			result = h.hash
+			if h.salt != "" {
+				result += " salt=" + h.salt
+			}
+			ok++
+		}
+
+		if _, err := fmt.Fprintln(out, result); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "total=%d ok=%d skipped=%d\n", total, ok, skipped)
+	if cfg.genSalt > 0 {
+		fmt.Fprintln(os.Stderr, storeSaltNotice)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// File: ssn_hash_template.go
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateData is what a -template sees for each record. Like Result, it
+// has no field for the SSN or the salt, and parseOutputTemplate rejects
+// templates that try to reference one.
+type templateData struct {
+	Hash          string
+	MaskedSSN     string // e.g. XXX-XX-6789; "" if the input does not normalize
+	Algo          string
+	Encoding      string // "" for argon2id and pbkdf2
+	GeneratedSalt string // hex, with -gen-salt only
+	OldHash       string // hash under the -salt-old salt, with -salt-old only
+}
+
+// templateFields lists the fields a -template may reference.
+var templateFields = map[string]bool{
+	"Hash":          true,
+	"MaskedSSN":     true,
+	"Algo":          true,
+	"Encoding":      true,
+	"GeneratedSalt": true,
+	"OldHash":       true,
+}
+
+// parseOutputTemplate parses a -template string once at startup. Every
+// field reference is checked against templateData before any record is
+// processed, so a template naming the raw SSN (or any other unknown field)
+// fails up front instead of on the first row.
+func parseOutputTemplate(text string) (*template.Template, error) {
+	t, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing -template: %w", err)
+	}
+	for _, def := range t.Templates() {
+		if def.Tree == nil {
+			continue
+		}
+		if err := checkTemplateFields(def.Tree.Root); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// checkTemplateFields walks a parsed template and rejects any field that
+// templateData does not provide.
+func checkTemplateFields(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkTemplateFields(c); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkTemplateFields(n.Pipe)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			return checkTemplateFields(n.Pipe)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := checkTemplateFields(c); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if err := checkTemplateFields(a); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		if err := checkTemplateFields(n.Node); err != nil {
+			return err
+		}
+		return checkFieldNames(n.Field)
+	case *parse.FieldNode:
+		return checkFieldNames(n.Ident)
+	case *parse.VariableNode:
+		// $.Field and $x.Field; only $ itself is known to hold templateData.
+		if len(n.Ident) > 1 {
+			return checkFieldNames(n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+func checkBranch(b *parse.BranchNode) error {
+	if err := checkTemplateFields(b.Pipe); err != nil {
+		return err
+	}
+	if err := checkTemplateFields(b.List); err != nil {
+		return err
+	}
+	return checkTemplateFields(b.ElseList)
+}
+
+// checkFieldNames checks a chain of field names such as .Hash.Foo. Only the
+// first name is a templateData field; strings have no fields of their own,
+// so anything after it is rejected too.
+func checkFieldNames(idents []string) error {
+	if len(idents) == 0 {
+		return nil
+	}
+	name := idents[0]
+	if !templateFields[name] {
+		if strings.Contains(strings.ToUpper(name), "SSN") || strings.Contains(strings.ToUpper(name), "EIN") {
+			return fmt.Errorf("-template may not reference .%s: the raw identifier is never available (use .MaskedSSN)", name)
+		}
+		return fmt.Errorf("-template references unknown field .%s (want Hash, MaskedSSN, Algo, Encoding, GeneratedSalt or OldHash)", name)
+	}
+	if len(idents) > 1 {
+		return fmt.Errorf("-template references unknown field .%s", strings.Join(idents, "."))
+	}
+	return nil
+}
+
+// templateData returns the -template fields for id, which hashed to h.
+func (c ssnConfig) templateData(id string, h hashed) templateData {
+	d := templateData{Hash: h.hash, MaskedSSN: c.id.mask(id), Algo: c.hash.AlgoName(), Encoding: c.hash.Encoding, GeneratedSalt: h.salt, OldHash: h.old}
+	if c.hash.KDF() {
+		d.Encoding = ""
+	}
+	return d
+}
+
+// renderTemplate executes t for one record.
+func renderTemplate(t *template.Template, d templateData) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, d); err != nil {
+		return "", fmt.Errorf("executing -template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,112 @@
+// File: ssn_hash_vectors.go
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// testVector is one input for -test-vectors. Its expected hash is not
+// stored: it is computed by the same code path the CLI uses, so the table
+// can never drift from actual behavior.
+type testVector struct {
+	salt, ssn string
+	hash      ssnhash.Config
+}
+
+// vectorConfig returns the default construction with the given algorithm
+// and encoding, adjusted by opts.
+func vectorConfig(algo, enc string, opts ...func(*ssnhash.Config)) ssnhash.Config {
+	c := ssnhash.Config{Algo: algo, Encoding: enc, Rounds: 1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+var (
+	withHMAC   = func(c *ssnhash.Config) { c.HMAC = true }
+	withFramed = func(c *ssnhash.Config) { c.Framed = true }
+	withSep    = func(c *ssnhash.Config) { c.Sep = []byte(":") }
+	withRounds = func(c *ssnhash.Config) { c.Rounds = 1000 }
+	withPBKDF2 = func(c *ssnhash.Config) { c.PBKDF2 = &ssnhash.DefaultPBKDF2Params }
+	withTrunc  = func(c *ssnhash.Config) { c.Truncate = 8 }
+)
+
+// testVectors covers dashed and undashed input, plain concatenation against
+// HMAC and PBKDF2, every algorithm and every encoding. Append to it, but never change
+// an existing entry: partners diff against the published table.
+var testVectors = []testVector{
+	{"s", "123-45-6789", vectorConfig("sha256", "hex")},
+	{"s", "123456789", vectorConfig("sha256", "hex")},
+	{"s", " 123 45 6789 ", vectorConfig("sha256", "hex")},
+	{"s", "123-45-6789", vectorConfig("sha256", "hexupper")},
+	{"s", "123-45-6789", vectorConfig("sha256", "base64")},
+	{"s", "123-45-6789", vectorConfig("sha256", "base64url")},
+	{"s", "123-45-6789", vectorConfig("sha512", "hex")},
+	{"s", "123-45-6789", vectorConfig("sha512_256", "hex")},
+	{"s", "123-45-6789", vectorConfig("sha3_256", "hex")},
+	{"s", "123-45-6789", vectorConfig("sha256", "hex", withHMAC)},
+	{"s", "123456789", vectorConfig("sha256", "hex", withHMAC)},
+	{"s", "123-45-6789", vectorConfig("sha512", "base64", withHMAC)},
+	{"s", "123-45-6789", vectorConfig("sha256", "hex", withFramed)},
+	{"s", "123-45-6789", vectorConfig("sha256", "hex", withSep)},
+	{"s", "123-45-6789", vectorConfig("sha256", "hex", withRounds)},
+	{"a longer salt, with spaces", "987-65-4321", vectorConfig("sha256", "hex")},
+	{"a longer salt, with spaces", "987-65-4321", vectorConfig("sha256", "hex", withHMAC)},
+	{"0123456789abcdef", "123-45-6789", vectorConfig("sha256", "hex", withPBKDF2)},
+	{"0123456789abcdef", "123-45-6789", vectorConfig("sha512", "hex", withPBKDF2)},
+	{"s", "123-45-6789", vectorConfig("sha256", "hex", withTrunc)},
+}
+
+// configOptions describes the settings of c not covered by its algo and
+// encoding columns, as the flags that select them.
+func configOptions(c ssnhash.Config) string {
+	var opts []string
+	if c.Framed {
+		opts = append(opts, "-framed")
+	}
+	if len(c.Sep) > 0 {
+		opts = append(opts, fmt.Sprintf("-sep=%s", c.Sep))
+	}
+	if c.Rounds > 1 {
+		opts = append(opts, fmt.Sprintf("-rounds=%d", c.Rounds))
+	}
+	if c.Truncate > 0 {
+		opts = append(opts, fmt.Sprintf("-truncate=%d", c.Truncate))
+	}
+	if c.PBKDF2 != nil {
+		opts = append(opts, fmt.Sprintf("-iterations=%d -keylen=%d", c.PBKDF2.Iterations, c.PBKDF2.KeyLen))
+	}
+	if len(opts) == 0 {
+		return "-"
+	}
+	return strings.Join(opts, " ")
+}
+
+// runTestVectors writes the test-vector table to w as tab-separated
+// columns: salt and ssn (quoted; the ssn as given, before normalization),
+// algo, encoding, other options and the resulting hash.
+func runTestVectors(w io.Writer) error {
+	fmt.Fprintln(w, "salt\tssn\talgo\tencoding\toptions\thash")
+	for _, v := range testVectors {
+		input, err := prepareID(v.ssn, idTypes["ssn"], false, false)
+		if err != nil {
+			return fmt.Errorf("test vector %q: %w", v.ssn, err)
+		}
+		h, err := v.hash.Hash([]byte(input), []byte(v.salt))
+		if err != nil {
+			return fmt.Errorf("test vector %q: %w", v.ssn, err)
+		}
+		enc := v.hash.Encoding
+		if v.hash.KDF() {
+			enc = "-"
+		}
+		fmt.Fprintf(w, "%q\t%q\t%s\t%s\t%s\t%s\n", v.salt, v.ssn, v.hash.AlgoName(), enc, configOptions(v.hash), h)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// File: ssn_hash_verify.go
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Columns of a -verify-in file.
+const (
+	verifySSNColumn  = 0
+	verifyHashColumn = 1
+)
+
+// verifyCounts tallies the rows of a -verify-in run.
+type verifyCounts struct {
+	matched, mismatched, failed int
+}
+
+// ok reports whether every row verified.
+func (c verifyCounts) ok() bool {
+	return c.mismatched == 0 && c.failed == 0
+}
+
+// runVerifyBatch reads a CSV of SSN,hash pairs from path, recomputes each
+// hash under cfg and writes one report line per row to w: "match",
+// "MISMATCH", or the reason the row could not be checked. The report
+// carries only line numbers, never the SSN. Hashes are compared in
+// constant time.
+func runVerifyBatch(path string, header bool, w io.Writer, cfg ssnConfig) (verifyCounts, error) {
+	var counts verifyCounts
+	inFile, err := openMaybeGzip(path, false)
+	if err != nil {
+		return counts, fmt.Errorf("opening input: %w", err)
+	}
+	defer inFile.Close()
+
+	r := csv.NewReader(inFile)
+	r.FieldsPerRecord = -1
+	if header {
+		if _, err := r.Read(); err != nil && err != io.EOF {
+			return counts, fmt.Errorf("reading header: %w", err)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	err = scanRows(r, verifySSNColumn, func(line int, record []string) bool {
+		if len(record) <= verifyHashColumn {
+			fmt.Fprintf(bw, "line %d: error: row with %d columns (want SSN,hash)\n", line, len(record))
+			counts.failed++
+			return true
+		}
+		ok, err := cfg.verify(record[verifySSNColumn], record[verifyHashColumn])
+		switch {
+		case err != nil:
+			fmt.Fprintf(bw, "line %d: error: %v\n", line, err)
+			counts.failed++
+		case ok:
+			fmt.Fprintf(bw, "line %d: match\n", line)
+			counts.matched++
+		default:
+			fmt.Fprintf(bw, "line %d: MISMATCH\n", line)
+			counts.mismatched++
+		}
+		return true
+	}, func(line int, reason string) {
+		fmt.Fprintf(bw, "line %d: error: %s\n", line, reason)
+		counts.failed++
+	})
+	if err != nil {
+		return counts, err
+	}
+	if err := bw.Flush(); err != nil {
+		return counts, fmt.Errorf("writing report: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Verified %d rows: %d matched, %d mismatched, %d could not be checked\n",
+		counts.matched+counts.mismatched+counts.failed, counts.matched, counts.mismatched, counts.failed)
+	return counts, nil
+}
@@ -0,0 +1,37 @@
+// File: ssn_hash_version.go
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/IDObjects/main/ssnhash"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%FT%TZ)" -o ssn_hash ./cmd/ssn_hash
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+// printVersion writes the build metadata followed by every default that
+// affects the hashes produced, so an output file can be traced back to the
+// exact configuration of the binary that wrote it. A change to any of these
+// lines means stored hashes may no longer match.
+func printVersion(w io.Writer) {
+	fmt.Fprintf(w, "ssn_hash %s (commit %s, built %s, %s)\n", version, commit, date, runtime.Version())
+	fmt.Fprintln(w, "defaults:")
+	fmt.Fprintf(w, "  algo:          %s\n", ssnhash.DefaultAlgo)
+	fmt.Fprintf(w, "  encoding:      %s\n", ssnhash.DefaultEncoding)
+	fmt.Fprintln(w, "  construction:  H(pepper || salt || sep || ssn), unframed, 1 round, empty sep")
+	fmt.Fprintln(w, "  normalization: strip whitespace, dashes and spaces; require 9 ASCII digits (-raw disables)")
+	fmt.Fprintln(w, "  salt:          used as given (-normalize-salt off)")
+	p := ssnhash.DefaultArgon2Params
+	fmt.Fprintf(w, "  argon2id:      m=%d,t=%d,p=%d, %d-byte key\n", p.Memory, p.Iterations, p.Parallelism, p.KeyLen)
+	fmt.Fprintf(w, "  pbkdf2:        i=%d, %d-byte key\n", ssnhash.DefaultPBKDF2Params.Iterations, ssnhash.DefaultPBKDF2Params.KeyLen)
+}
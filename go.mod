@@ -0,0 +1,11 @@
+module github.com/IDObjects/main
+
+go 1.24
+
+require (
+	golang.org/x/crypto v0.31.0
+	golang.org/x/term v0.27.0
+	golang.org/x/text v0.21.0
+)
+
+require golang.org/x/sys v0.28.0 // indirect
@@ -0,0 +1,189 @@
+// File: ssn_hash.go
+
+// Package ssnhash normalizes, validates and hashes Social Security numbers
+// and EINs. It is the library behind the ssn_hash command (cmd/ssn_hash):
+// HashSSN and the other one-shot functions cover the common cases, and
+// Config reproduces any construction the command can be configured for.
+package ssnhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NormalizeSSN canonicalizes an SSN so that dashed and undashed forms hash
+// identically: surrounding whitespace, dashes and spaces are removed and the
+// remainder must be exactly nine ASCII digits. Any other character, such as
+// the letter O typed for a zero, is reported with its position. Errors are
+// *ValidationError values with Rule ErrInvalidLength or ErrNonDigit.
+func NormalizeSSN(ssn string) (string, error) {
+	return normalizeDigits(ssn, "SSN")
+}
+
+// NormalizeSSNBytes is NormalizeSSN for an SSN held in a byte slice, which
+// unlike a string can be wiped afterwards. The result is a new slice, which
+// the caller must zeroize; ssn is not modified.
+func NormalizeSSNBytes(ssn []byte) ([]byte, error) {
+	return normalizeDigitBytes(ssn, "SSN")
+}
+
+// normalizeDigits strips surrounding whitespace and every dash and space
+// from id, and checks that exactly nine ASCII digits remain. kind names the
+// identifier in errors. Positions count characters from 1 in the original
+// input, so they can be matched against the source record.
+func normalizeDigits(id, kind string) (string, error) {
+	buf := []byte(id)
+	defer Zeroize(buf)
+	digits, err := normalizeDigitBytes(buf, kind)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(digits)
+	return string(digits), nil
+}
+
+// normalizeDigitBytes is normalizeDigits for input held in a byte slice.
+// The result is a new slice, which the caller must zeroize.
+func normalizeDigitBytes(id []byte, kind string) ([]byte, error) {
+	trimmed := bytes.TrimLeftFunc(id, unicode.IsSpace)
+	pos := utf8.RuneCount(id) - utf8.RuneCount(trimmed)
+	trimmed = bytes.TrimRightFunc(trimmed, unicode.IsSpace)
+
+	// Appending stops at nine digits, so the buffer never grows and leaves
+	// a stale copy behind; n keeps counting for the error message.
+	digits := make([]byte, 0, 9)
+	n := 0
+	for len(trimmed) > 0 {
+		r, size := utf8.DecodeRune(trimmed)
+		trimmed = trimmed[size:]
+		pos++
+		switch {
+		case r == '-' || r == ' ':
+		case r >= '0' && r <= '9':
+			if n++; n <= 9 {
+				digits = append(digits, byte(r))
+			}
+		default:
+			Zeroize(digits)
+			return nil, ruleError(kind, ErrNonDigit, "%s contains non-digit character %q at position %d", kind, r, pos)
+		}
+	}
+	if n != 9 {
+		Zeroize(digits)
+		return nil, ruleError(kind, ErrInvalidLength, "%s must contain exactly 9 digits, got %d", kind, n)
+	}
+	return digits, nil
+}
+
+// MaskSSN renders an SSN with only its last four digits visible, e.g.
+// "XXX-XX-6789". Dashed and undashed input both work because the SSN is
+// normalized first; if normalization fails, MaskSSN returns "" rather than
+// echoing any part of the input.
+func MaskSSN(ssn string) string {
+	digits, err := NormalizeSSN(ssn)
+	if err != nil {
+		return ""
+	}
+	return "XXX-XX-" + digits[5:]
+}
+
+// ValidateSSN checks an SSN against the SSA's structural rules: the area
+// number (first three digits) may not be 000, 666 or 900-999, the group
+// number (middle two) may not be 00 and the serial number (last four) may not
+// be 0000. The input is normalized first, so dashed forms are accepted.
+// A violation is reported as a *ValidationError naming the rule, e.g.
+// ErrInvalidAreaNumber.
+func ValidateSSN(ssn string) error {
+	digits, err := NormalizeSSN(ssn)
+	if err != nil {
+		return err
+	}
+
+	area, group, serial := digits[:3], digits[3:5], digits[5:]
+	switch {
+	case area == "000":
+		return ruleError("SSN", ErrInvalidAreaNumber, "area number (first three digits) cannot be 000")
+	case area == "666":
+		return ruleError("SSN", ErrInvalidAreaNumber, "area number (first three digits) cannot be 666")
+	case area[0] == '9':
+		return ruleError("SSN", ErrInvalidAreaNumber, "area number (first three digits) cannot be in the range 900-999")
+	case group == "00":
+		return ruleError("SSN", ErrInvalidGroupNumber, "group number (digits 4-5) cannot be 00")
+	case serial == "0000":
+		return ruleError("SSN", ErrInvalidSerialNumber, "serial number (last four digits) cannot be 0000")
+	}
+	return nil
+}
+
+// HashSSNBytes returns the raw SHA-256 digest of salt+ssn, for callers that
+// want to apply their own encoding.
+// The concatenation buffer is zeroized before returning; wiping ssn and salt
+// is left to the caller.
+func HashSSNBytes(ssn, salt []byte) [32]byte {
+	plain := concat(salt, ssn)
+	defer Zeroize(plain)
+	return sha256.Sum256(plain)
+}
+
+// HashSSN returns the hex-encoded SHA-256 digest of salt+ssn.
+func HashSSN(ssn, salt string) string {
+	return HashSSNSep(ssn, salt, "")
+}
+
+// HashSSNSep returns the hex-encoded SHA-256 digest of salt+sep+ssn, for
+// reproducing hashes from systems that joined the two with a separator such
+// as ":". An empty sep is identical to HashSSN. For example,
+// HashSSNSep("123456789", "s", ":") is
+// 6ac78c04e065ba9923d50331a6cdc74ca5e6dc92573ceb726a3043fc551bad7c, the
+// SHA-256 of "s:123456789".
+func HashSSNSep(ssn, salt, sep string) string {
+	ssnBuf, saltBuf := []byte(ssn), []byte(salt)
+	defer Zeroize(ssnBuf)
+	defer Zeroize(saltBuf)
+	prefix := concat(saltBuf, []byte(sep))
+	defer Zeroize(prefix)
+	sum := HashSSNBytes(ssnBuf, prefix)
+	return hex.EncodeToString(sum[:])
+}
+
+// HMACSSN returns the hex-encoded HMAC-SHA256 of ssn keyed with salt. Unlike
+// HashSSN, the salt acts as a real key, so the result is not exposed to
+// length-extension. For example, key "Jefe" and message
+// "what do ya want for nothing?" (RFC 4231 test case 2) yield
+// 5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843.
+func HMACSSN(ssn, salt string) string {
+	ssnBuf, saltBuf := []byte(ssn), []byte(salt)
+	defer Zeroize(ssnBuf)
+	defer Zeroize(saltBuf)
+	sum, _ := hmacWith("sha256", saltBuf, ssnBuf)
+	return hex.EncodeToString(sum)
+}
+
+// VerifySSN reports whether the SHA-256 digest of salt+ssn matches
+// expectedHex. The digests are compared in constant time on their raw bytes.
+func VerifySSN(ssn, salt, expectedHex string) (bool, error) {
+	ssnBuf, saltBuf := []byte(ssn), []byte(salt)
+	defer Zeroize(ssnBuf)
+	defer Zeroize(saltBuf)
+	sum := HashSSNBytes(ssnBuf, saltBuf)
+	return VerifyDigest(sum[:], expectedHex, "hex")
+}
+
+// VerifyDigest compares sum with the expected digest, given in the named
+// encoding, in constant time.
+func VerifyDigest(sum []byte, expectedEncoded, enc string) (bool, error) {
+	expected, err := DecodeDigest(strings.TrimSpace(expectedEncoded), enc)
+	if err != nil {
+		return false, fmt.Errorf("decoding expected hash: %w", err)
+	}
+	if len(expected) != len(sum) {
+		return false, fmt.Errorf("expected hash must be %d bytes, got %d", len(sum), len(expected))
+	}
+	return subtle.ConstantTimeCompare(sum, expected) == 1, nil
+}
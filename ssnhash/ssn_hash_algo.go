@@ -0,0 +1,282 @@
+// File: ssn_hash_algo.go
+
+package ssnhash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// DefaultAlgo is the algorithm used when -algo is not given. Changing it
+// would invalidate every hash produced so far.
+const DefaultAlgo = "sha256"
+
+// algoLabels maps each supported -algo value to its display name.
+var algoLabels = map[string]string{
+	"sha256":     "SHA-256",
+	"sha512":     "SHA-512",
+	"sha512_256": "SHA-512/256",
+	"sha3_256":   "SHA3-256",
+}
+
+// newHash returns a fresh hash.Hash for the named algorithm.
+func newHash(algo string) (hash.Hash, error) {
+	switch algo {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "sha512_256":
+		return sha512.New512_256(), nil
+	case "sha3_256":
+		return sha3.New256(), nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q (want sha256, sha512, sha512_256 or sha3_256)", algo)
+}
+
+// DigestSize returns the length in bytes of the named algorithm's digest,
+// the largest valid Config.Truncate.
+func DigestSize(algo string) (int, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return 0, err
+	}
+	return h.Size(), nil
+}
+
+// hashWith returns the digest of data under the named algorithm.
+func hashWith(algo string, data []byte) ([]byte, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// Config describes how a prepared SSN and salt are turned into a digest;
+// each field corresponds to the ssn_hash flag of the same name. Algo and
+// Encoding must be set, e.g. to DefaultAlgo and DefaultEncoding; every
+// other zero value selects the plain construction.
+//
+// When a pepper is configured it always comes first, so the byte strings fed
+// to each construction are, exactly:
+//
+//	plain:  H(pepper || salt || sep || ssn)
+//	-hmac:  HMAC(key = pepper || salt, message = ssn)
+//	argon2: Argon2id(password = pepper || ssn, salt = salt)
+//	pbkdf2: PBKDF2-HMAC-H(password = pepper || ssn, salt = salt)
+//
+// With Framed set, every || above is replaced by frameFields, so salt "12"
+// with ssn "3456789" no longer produces the same preimage as salt "123" with
+// ssn "456789". Changing this order silently invalidates every stored hash.
+type Config struct {
+	Algo     string // sha256, sha512, sha512_256 or sha3_256
+	Encoding string // see EncodeDigest
+	HMAC     bool   // key the hash with the salt instead of prefixing it
+	Pepper   []byte // application-wide secret, never printed
+	Framed   bool   // length-prefix each field instead of concatenating
+	Sep      []byte // inserted between salt and ssn in the plain construction
+	Rounds   int    // total hash rounds; see IterateHash
+	Truncate int    // when > 0, keep only this many leading digest bytes
+
+	Argon2 *Argon2Params // when set, stretch with Argon2id instead
+	PBKDF2 *PBKDF2Params // when set, derive with PBKDF2-HMAC-<algo> instead
+}
+
+// KDF reports whether the output is a PHC string from Argon2id or PBKDF2
+// rather than an encoded digest.
+func (c Config) KDF() bool {
+	return c.Argon2 != nil || c.PBKDF2 != nil
+}
+
+// Hash returns the final output for ssn: the encoded digest, or the
+// PHC string when Argon2id or PBKDF2 is enabled.
+func (c Config) Hash(ssn, salt []byte) (string, error) {
+	if c.KDF() {
+		password := c.join(c.Pepper, ssn)
+		defer Zeroize(password)
+		if c.PBKDF2 != nil {
+			return derivePBKDF2(c.Algo, password, salt, *c.PBKDF2)
+		}
+		return stretch(password, salt, *c.Argon2)
+	}
+	sum, err := c.Digest(ssn, salt)
+	if err != nil {
+		return "", err
+	}
+	return EncodeDigest(sum, c.Encoding)
+}
+
+// Digest hashes pepper+salt+ssn, or computes HMAC(key=pepper+salt,
+// message=ssn) when c.HMAC is set, then stretches the result to c.Rounds
+// rounds. With c.Truncate set, the raw digest is cut to its first
+// c.Truncate bytes, before any encoding.
+func (c Config) Digest(ssn, salt []byte) ([]byte, error) {
+	var sum []byte
+	var err error
+	if c.HMAC {
+		key := c.join(c.Pepper, salt)
+		defer Zeroize(key)
+		sum, err = hmacWith(c.Algo, key, ssn)
+	} else {
+		parts := [][]byte{salt, ssn}
+		if len(c.Sep) > 0 {
+			parts = [][]byte{salt, c.Sep, ssn}
+		}
+		plain := c.join(c.Pepper, parts...)
+		defer Zeroize(plain)
+		sum, err = hashWith(c.Algo, plain)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sum, err = iterate(c.Algo, sum, salt, c.Rounds); err != nil {
+		return nil, err
+	}
+	if c.Truncate > 0 {
+		sum = sum[:c.Truncate]
+	}
+	return sum, nil
+}
+
+// IterateHash stretches a SHA-256 digest: initial is taken as the output of
+// round one and each further round computes SHA-256(salt || previous), until
+// rounds rounds have run. Mixing the salt back in keeps the chain from
+// collapsing onto a fixed point. rounds <= 1 returns initial unchanged.
+//
+// This is a cheap stopgap, not a substitute for StretchSSN.
+func IterateHash(initial []byte, salt []byte, rounds int) []byte {
+	sum, _ := iterate("sha256", initial, salt, rounds)
+	return sum
+}
+
+// iterate is IterateHash for any supported algorithm.
+func iterate(algo string, initial, salt []byte, rounds int) ([]byte, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	sum := append([]byte(nil), initial...)
+	for i := 1; i < rounds; i++ {
+		h.Reset()
+		h.Write(salt)
+		h.Write(sum)
+		sum = h.Sum(sum[:0])
+	}
+	return sum, nil
+}
+
+// join combines the construction's input fields, dropping an unset pepper,
+// either by plain concatenation or, with c.Framed, by frameFields. The
+// caller must zeroize the result.
+func (c Config) join(pepper []byte, rest ...[]byte) []byte {
+	parts := rest
+	if len(pepper) > 0 {
+		parts = append([][]byte{pepper}, rest...)
+	}
+	if c.Framed {
+		return frameFields(parts...)
+	}
+	return concat(parts...)
+}
+
+// frameFields encodes each part as a 4-byte big-endian length followed by
+// its bytes, so distinct field lists can never share a preimage: "12"+"345"
+// and "123"+"45" concatenate identically but frame as
+// 00000002 3132 00000003 333435 and 00000003 313233 00000002 3435.
+func frameFields(parts ...[]byte) []byte {
+	n := 0
+	for _, p := range parts {
+		n += 4 + len(p)
+	}
+	buf := make([]byte, 0, n)
+	for _, p := range parts {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(p)))
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+// Label describes the construction for human-readable output, e.g.
+// "SHA-256(salt+ssn)", naming the identifier field after the -idtype.
+func (c Config) Label(field string) string {
+	p := ""
+	if len(c.Pepper) > 0 {
+		p = "pepper+"
+	}
+	sep := "+"
+	if c.Framed {
+		sep = "|"
+		if p != "" {
+			p = "pepper|"
+		}
+	}
+	var label string
+	switch {
+	case c.Argon2 != nil:
+		return "Argon2id(salt, " + p + field + ")"
+	case c.PBKDF2 != nil:
+		return "PBKDF2-HMAC-" + algoLabels[c.Algo] + "(salt, " + p + field + ")"
+	case c.HMAC:
+		label = "HMAC-" + algoLabels[c.Algo] + "(key=" + p + "salt, " + field + ")"
+	default:
+		if len(c.Sep) > 0 {
+			sep += fmt.Sprintf("%q", c.Sep) + sep
+		}
+		label = algoLabels[c.Algo] + "(" + p + "salt" + sep + field + ")"
+	}
+	if c.Rounds > 1 {
+		label += fmt.Sprintf(" x%d rounds", c.Rounds)
+	}
+	if c.Truncate > 0 {
+		label += fmt.Sprintf(" truncated to %d bytes", c.Truncate)
+	}
+	return label
+}
+
+// AlgoName identifies the construction in machine-readable output.
+func (c Config) AlgoName() string {
+	if c.Argon2 != nil {
+		return "argon2id"
+	}
+	if c.PBKDF2 != nil {
+		return "pbkdf2_" + c.Algo
+	}
+	if c.HMAC {
+		return "hmac_" + c.Algo
+	}
+	return c.Algo
+}
+
+// ColumnName is the batch-mode header for the hash column.
+func (c Config) ColumnName() string {
+	if c.Argon2 != nil {
+		return "argon2id"
+	}
+	if c.PBKDF2 != nil {
+		return "pbkdf2_" + c.Algo
+	}
+	if c.HMAC {
+		return "hmac_" + c.Algo
+	}
+	return "hash_" + c.Algo
+}
+
+// hmacWith returns the HMAC of msg under key using the named algorithm.
+func hmacWith(algo string, key, msg []byte) ([]byte, error) {
+	if _, err := newHash(algo); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(func() hash.Hash {
+		h, _ := newHash(algo)
+		return h
+	}, key)
+	mac.Write(msg)
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,48 @@
+// File: ssn_hash_algo_test.go
+
+package ssnhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+// TestFramedCollision checks that the plain construction cannot tell salt
+// "12" with ssn "3456789" from salt "123" with ssn "456789", and that
+// Framed separates them.
+func TestFramedCollision(t *testing.T) {
+	digest := func(c Config, ssn, salt string) []byte {
+		t.Helper()
+		sum, err := c.Digest([]byte(ssn), []byte(salt))
+		if err != nil {
+			t.Fatalf("Digest(%q, %q): %v", ssn, salt, err)
+		}
+		return sum
+	}
+
+	plain := Config{Algo: DefaultAlgo}
+	if a, b := digest(plain, "3456789", "12"), digest(plain, "456789", "123"); !bytes.Equal(a, b) {
+		t.Errorf("plain digests differ: %x vs %x", a, b)
+	}
+
+	framed := Config{Algo: DefaultAlgo, Framed: true}
+	if a, b := digest(framed, "3456789", "12"), digest(framed, "456789", "123"); bytes.Equal(a, b) {
+		t.Errorf("framed digests collide: %x", a)
+	}
+}
+
+// BenchmarkIterateHash measures -rounds at several counts, to help pick one
+// that fits a latency budget.
+func BenchmarkIterateHash(b *testing.B) {
+	initial := sha256.Sum256([]byte("s123456789"))
+	salt := []byte("s")
+	for _, rounds := range []int{1, 1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("rounds=%d", rounds), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				IterateHash(initial[:], salt, rounds)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// File: ssn_hash_argon2.go
+
+package ssnhash
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params are the tunable Argon2id cost parameters.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	KeyLen      uint32 // bytes of derived output
+}
+
+// DefaultArgon2Params follows the second recommended option of RFC 9106
+// (64 MiB, 3 passes, 4 lanes).
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	KeyLen:      32,
+}
+
+// Minimum Argon2id costs accepted by StretchSSN, taken from the OWASP
+// password storage guidance (19 MiB, 2 passes, 1 lane).
+const (
+	MinArgon2Memory     = 19 * 1024
+	MinArgon2Iterations = 2
+	MinArgon2SaltLen    = 8
+	MinArgon2KeyLen     = 16
+)
+
+// Validate rejects parameters too weak to be worth storing.
+func (p Argon2Params) Validate() error {
+	switch {
+	case p.Memory < MinArgon2Memory:
+		return fmt.Errorf("argon2 memory must be at least %d KiB, got %d", MinArgon2Memory, p.Memory)
+	case p.Iterations < MinArgon2Iterations:
+		return fmt.Errorf("argon2 iterations must be at least %d, got %d", MinArgon2Iterations, p.Iterations)
+	case p.Parallelism < 1:
+		return fmt.Errorf("argon2 parallelism must be at least 1")
+	case p.KeyLen < MinArgon2KeyLen:
+		return fmt.Errorf("argon2 key length must be at least %d bytes, got %d", MinArgon2KeyLen, p.KeyLen)
+	}
+	return nil
+}
+
+// StretchSSN derives an Argon2id key from ssn using salt as the Argon2 salt
+// and returns it as a PHC string, e.g.
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 key>
+//
+// so the parameters needed for later verification travel with the hash.
+func StretchSSN(ssn, salt string, params Argon2Params) (string, error) {
+	ssnBuf, saltBuf := []byte(ssn), []byte(salt)
+	defer Zeroize(ssnBuf)
+	defer Zeroize(saltBuf)
+	return stretch(ssnBuf, saltBuf, params)
+}
+
+// stretch is StretchSSN on byte slices; it does not wipe its arguments.
+func stretch(ssn, salt []byte, params Argon2Params) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	if len(salt) < MinArgon2SaltLen {
+		return "", fmt.Errorf("argon2 salt must be at least %d bytes, got %d", MinArgon2SaltLen, len(salt))
+	}
+
+	key := argon2.IDKey(ssn, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLen)
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
@@ -0,0 +1,58 @@
+// File: ssn_hash_ein.go
+
+package ssnhash
+
+import "fmt"
+
+// validEINPrefixes lists the two-digit EIN prefixes the IRS assigns, taken
+// from its published campus table. 00, 07-09, 17-19, 28, 29, 49, 69, 70,
+// 78, 79, 89, 96 and 97 are not in use.
+var validEINPrefixes = map[string]bool{}
+
+func init() {
+	ranges := [][2]int{
+		{1, 6}, {10, 16}, {20, 27}, {30, 48}, {50, 68},
+		{71, 77}, {80, 88}, {90, 95}, {98, 99},
+	}
+	for _, r := range ranges {
+		for p := r[0]; p <= r[1]; p++ {
+			validEINPrefixes[fmt.Sprintf("%02d", p)] = true
+		}
+	}
+}
+
+// NormalizeEIN canonicalizes an Employer Identification Number so that
+// "12-3456789" and "123456789" hash identically: surrounding whitespace,
+// dashes and spaces are removed and the remainder must be exactly nine
+// digits.
+func NormalizeEIN(ein string) (string, error) {
+	return normalizeDigits(ein, "EIN")
+}
+
+// NormalizeEINBytes is the EIN counterpart of NormalizeSSNBytes.
+func NormalizeEINBytes(ein []byte) ([]byte, error) {
+	return normalizeDigitBytes(ein, "EIN")
+}
+
+// ValidateEIN checks that an EIN's two-digit prefix is one the IRS assigns.
+// The input is normalized first, so the dashed XX-XXXXXXX form is accepted.
+func ValidateEIN(ein string) error {
+	digits, err := NormalizeEIN(ein)
+	if err != nil {
+		return err
+	}
+	if !validEINPrefixes[digits[:2]] {
+		return ruleError("EIN", ErrUnassignedPrefix, "prefix (first two digits) %s is not assigned by the IRS", digits[:2])
+	}
+	return nil
+}
+
+// MaskEIN is the EIN counterpart of MaskSSN: "12-3456789" becomes
+// "XX-XXX6789", and "" is returned if the EIN does not normalize.
+func MaskEIN(ein string) string {
+	digits, err := NormalizeEIN(ein)
+	if err != nil {
+		return ""
+	}
+	return "XX-XXX" + digits[5:]
+}
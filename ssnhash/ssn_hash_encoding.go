@@ -0,0 +1,43 @@
+// File: ssn_hash_encoding.go
+
+package ssnhash
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DefaultEncoding is the digest encoding used when -encoding is not given.
+const DefaultEncoding = "hex"
+
+// EncodeDigest renders sum in the named -encoding: "hex", "hexupper",
+// "base64" (standard, padded) or "base64url" (URL-safe, unpadded).
+func EncodeDigest(sum []byte, enc string) (string, error) {
+	switch enc {
+	case "hex":
+		return hex.EncodeToString(sum), nil
+	case "hexupper":
+		return strings.ToUpper(hex.EncodeToString(sum)), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(sum), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(sum), nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q (want hex, hexupper, base64 or base64url)", enc)
+}
+
+// DecodeDigest is the inverse of EncodeDigest. Hex input is accepted in
+// either case.
+func DecodeDigest(s, enc string) ([]byte, error) {
+	switch enc {
+	case "hex", "hexupper":
+		return hex.DecodeString(s)
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	case "base64url":
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unsupported encoding %q (want hex, hexupper, base64 or base64url)", enc)
+}
@@ -0,0 +1,28 @@
+// File: ssn_hash_encoding_test.go
+
+package ssnhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+// TestDigestRoundTrip checks that DecodeDigest inverts EncodeDigest for a
+// 32-byte digest in every supported encoding.
+func TestDigestRoundTrip(t *testing.T) {
+	sum := sha256.Sum256([]byte("s123456789"))
+	for _, enc := range []string{"hex", "hexupper", "base64", "base64url"} {
+		s, err := EncodeDigest(sum[:], enc)
+		if err != nil {
+			t.Fatalf("EncodeDigest(%s): %v", enc, err)
+		}
+		got, err := DecodeDigest(s, enc)
+		if err != nil {
+			t.Fatalf("DecodeDigest(%q, %s): %v", s, enc, err)
+		}
+		if !bytes.Equal(got, sum[:]) {
+			t.Errorf("%s round trip = %x, want %x", enc, got, sum)
+		}
+	}
+}
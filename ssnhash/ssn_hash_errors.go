@@ -0,0 +1,45 @@
+// File: ssn_hash_errors.go
+
+package ssnhash
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The rules an identifier can break. Every error returned by NormalizeSSN,
+// ValidateSSN, NormalizeEIN and ValidateEIN is a *ValidationError whose
+// Rule is one of these, so callers can branch with errors.Is: the first two
+// mean the input is not a well-formed nine-digit identifier, the rest that
+// it is well-formed but can never have been issued.
+var (
+	ErrInvalidLength       = errors.New("wrong number of digits")
+	ErrNonDigit            = errors.New("non-digit character")
+	ErrInvalidAreaNumber   = errors.New("invalid area number")
+	ErrInvalidGroupNumber  = errors.New("invalid group number")
+	ErrInvalidSerialNumber = errors.New("invalid serial number")
+	ErrUnassignedPrefix    = errors.New("unassigned EIN prefix")
+)
+
+// ValidationError describes why an identifier was rejected. Its message is
+// the human-readable one the CLI prints; use errors.As to get at the
+// fields, or errors.Is against Rule.
+type ValidationError struct {
+	Kind   string // "SSN" or "EIN"
+	Rule   error  // one of the Err* values above
+	Detail string // e.g. "area number (first three digits) cannot be 000"
+}
+
+func (e *ValidationError) Error() string {
+	return e.Detail
+}
+
+// Unwrap makes errors.Is(err, ErrInvalidAreaNumber) and friends work.
+func (e *ValidationError) Unwrap() error {
+	return e.Rule
+}
+
+// ruleError returns a *ValidationError for kind with a formatted detail.
+func ruleError(kind string, rule error, format string, args ...any) error {
+	return &ValidationError{Kind: kind, Rule: rule, Detail: fmt.Sprintf(format, args...)}
+}
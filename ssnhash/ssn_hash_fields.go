@@ -0,0 +1,46 @@
+// File: ssn_hash_fields.go
+
+package ssnhash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Field is one named input to HashFields.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// HashFields returns the hex-encoded SHA-256 digest of an ordered list of
+// named fields under a domain-separation tag:
+//
+//	SHA-256(frame(tag) || frame(salt) || frame(name1) || frame(value1) || frame(name2) || ...)
+//
+// where frame(x) is len(x) as a 4-byte big-endian integer followed by the
+// bytes of x (see frameFields). The tag keeps hashes made for one purpose
+// from matching those made for another; include a version in it, e.g.
+// "acme/match-key/v1". Field order is significant and names are hashed
+// with their values, so {ssn, dob} and {dob, ssn} differ. Values are hashed
+// verbatim: canonicalize them first (NormalizeSSN, ISO 8601 dates, ...).
+//
+// For example, tag "example/v1", salt "s" and the fields ssn=123456789,
+// dob=1990-01-31 yield
+// da8286e21649d5d7078b2a3871a6ef2e576fe35a3392fca58ff841a41540b56f.
+func HashFields(tag string, fields []Field, salt string) string {
+	parts := make([][]byte, 0, 2+2*len(fields))
+	parts = append(parts, []byte(tag), []byte(salt))
+	for _, f := range fields {
+		parts = append(parts, []byte(f.Name), []byte(f.Value))
+	}
+	defer func() {
+		for _, p := range parts[1:] {
+			Zeroize(p)
+		}
+	}()
+	plain := frameFields(parts...)
+	defer Zeroize(plain)
+	sum := sha256.Sum256(plain)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+// File: ssn_hash_hasher.go
+
+package ssnhash
+
+import (
+	"encoding/hex"
+	"hash"
+)
+
+// Hasher computes the salted digest of an SSN that arrives in pieces, for
+// library callers reading it from a stream. It wraps a hash.Hash seeded
+// with the salt, so writing the SSN and calling Sum yields the same value
+// as the one-shot functions: New(salt, "sha256") fed "123456789" sums to
+// HashSSN("123456789", salt).
+//
+// A Hasher sees only raw bytes and cannot normalize them; write the SSN in
+// its canonical form (see NormalizeSSN) or the hash will not match.
+type Hasher struct {
+	h hash.Hash
+}
+
+// New returns a Hasher for the named -algo algorithm ("" selects the
+// default, sha256), already seeded with salt. It panics if algo is not
+// supported; algorithm names are expected to be constants.
+func New(salt string, algo string) *Hasher {
+	if algo == "" {
+		algo = DefaultAlgo
+	}
+	h, err := newHash(algo)
+	if err != nil {
+		panic("ssnhash: " + err.Error())
+	}
+	saltBuf := []byte(salt)
+	defer Zeroize(saltBuf)
+	h.Write(saltBuf)
+	return &Hasher{h: h}
+}
+
+// Write adds more of the SSN to the digest. It implements io.Writer, so a
+// Hasher can be the destination of io.Copy, and never returns an error.
+func (s *Hasher) Write(p []byte) (int, error) {
+	return s.h.Write(p)
+}
+
+// Sum returns the hex-encoded digest of the salt followed by everything
+// written so far. It does not change the Hasher's state.
+func (s *Hasher) Sum() string {
+	return hex.EncodeToString(s.h.Sum(nil))
+}
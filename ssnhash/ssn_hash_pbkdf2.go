@@ -0,0 +1,99 @@
+// File: ssn_hash_pbkdf2.go
+
+package ssnhash
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBKDF2Params are the tunable PBKDF2 cost parameters.
+type PBKDF2Params struct {
+	Iterations int
+	KeyLen     int // bytes of derived output
+}
+
+// DefaultPBKDF2Params follows the OWASP password storage guidance for
+// PBKDF2-HMAC-SHA256 (600,000 iterations).
+var DefaultPBKDF2Params = PBKDF2Params{
+	Iterations: 600000,
+	KeyLen:     32,
+}
+
+// Minimum PBKDF2 parameters accepted by DerivePBKDF2. The iteration count
+// and the 128-bit salt are the floors set by NIST SP 800-132.
+const (
+	MinPBKDF2Iterations = 1000
+	MinPBKDF2SaltLen    = 16
+	MinPBKDF2KeyLen     = 16
+)
+
+// MaxKeyLen caps -keylen for both PBKDF2 and Argon2id.
+const MaxKeyLen = 1 << 10
+
+// Validate rejects parameters too weak to be worth storing.
+func (p PBKDF2Params) Validate() error {
+	switch {
+	case p.Iterations < MinPBKDF2Iterations:
+		return fmt.Errorf("pbkdf2 iterations must be at least %d, got %d", MinPBKDF2Iterations, p.Iterations)
+	case p.KeyLen < MinPBKDF2KeyLen:
+		return fmt.Errorf("pbkdf2 key length must be at least %d bytes, got %d", MinPBKDF2KeyLen, p.KeyLen)
+	}
+	return nil
+}
+
+// DerivePBKDF2 derives a PBKDF2-HMAC-SHA256 key from ssn using salt as the
+// PBKDF2 salt and returns it as a PHC-style string, e.g.
+//
+//	$pbkdf2-sha256$i=600000,l=32$<base64 salt>$<base64 key>
+//
+// so the parameters needed for later verification travel with the key.
+// Any PBKDF2 implementation reproduces the key; for ssn "123456789", salt
+// "0123456789abcdef" and the default parameters it is
+// 2d16afb0f777913cb0cd1c6e2cd4dfeb45d110b744bfa162adc23bc3939ea3d4 in hex,
+// as computed by Python's hashlib.pbkdf2_hmac.
+func DerivePBKDF2(ssn, salt string, params PBKDF2Params) (string, error) {
+	ssnBuf, saltBuf := []byte(ssn), []byte(salt)
+	defer Zeroize(ssnBuf)
+	defer Zeroize(saltBuf)
+	return derivePBKDF2("sha256", ssnBuf, saltBuf, params)
+}
+
+// derivePBKDF2 is DerivePBKDF2 with the HMAC hash taken from -algo; it does
+// not wipe its arguments.
+func derivePBKDF2(algo string, ssn, salt []byte, params PBKDF2Params) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	if len(salt) < MinPBKDF2SaltLen {
+		return "", fmt.Errorf("pbkdf2 salt must be at least %d bytes, got %d", MinPBKDF2SaltLen, len(salt))
+	}
+	key, err := pbkdf2Key(algo, ssn, salt, params.Iterations, params.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(key)
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$pbkdf2-%s$i=%d,l=%d$%s$%s",
+		strings.ReplaceAll(algo, "_", "-"), params.Iterations, params.KeyLen,
+		b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// pbkdf2Key is the bare derivation, without parameter checks. With
+// password "passwd", salt "salt", one iteration and a 64-byte key,
+// PBKDF2-HMAC-SHA256 yields the RFC 7914 section 11 vector
+// 55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc
+// 49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783.
+func pbkdf2Key(algo string, password, salt []byte, iterations, keyLen int) ([]byte, error) {
+	if _, err := newHash(algo); err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key(password, salt, iterations, keyLen, func() hash.Hash {
+		h, _ := newHash(algo)
+		return h
+	}), nil
+}
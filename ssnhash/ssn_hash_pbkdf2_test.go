@@ -0,0 +1,35 @@
+// File: ssn_hash_pbkdf2_test.go
+
+package ssnhash
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+// TestPBKDF2Vectors checks the RFC 7914 vector quoted on pbkdf2Key and the
+// hashlib vector quoted on DerivePBKDF2.
+func TestPBKDF2Vectors(t *testing.T) {
+	const rfc7914 = "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	key, err := pbkdf2Key("sha256", []byte("passwd"), []byte("salt"), 1, 64)
+	if err != nil {
+		t.Fatalf("pbkdf2Key: %v", err)
+	}
+	if got := hex.EncodeToString(key); got != rfc7914 {
+		t.Errorf("pbkdf2Key(passwd, salt, 1, 64) = %s, want %s", got, rfc7914)
+	}
+
+	const salt = "0123456789abcdef"
+	want, _ := hex.DecodeString("2d16afb0f777913cb0cd1c6e2cd4dfeb45d110b744bfa162adc23bc3939ea3d4")
+	b64 := base64.RawStdEncoding
+	phc := "$pbkdf2-sha256$i=600000,l=32$" + b64.EncodeToString([]byte(salt)) + "$" + b64.EncodeToString(want)
+	got, err := DerivePBKDF2("123456789", salt, DefaultPBKDF2Params)
+	if err != nil {
+		t.Fatalf("DerivePBKDF2: %v", err)
+	}
+	if got != phc {
+		t.Errorf("DerivePBKDF2(%q, %q) = %s, want %s", "123456789", salt, got, phc)
+	}
+}
@@ -0,0 +1,37 @@
+// File: ssn_hash_salt.go
+
+package ssnhash
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSaltLen caps the length GenerateSalt accepts, and so -gen-salt.
+const MaxSaltLen = 1 << 10
+
+// GenerateSalt returns n cryptographically random bytes for use as a
+// per-record salt. A per-record salt must be stored next to its hash: without
+// it the hash can never be verified again.
+func GenerateSalt(n int) ([]byte, error) {
+	if n < 1 || n > MaxSaltLen {
+		return nil, fmt.Errorf("salt length must be between 1 and %d bytes, got %d", MaxSaltLen, n)
+	}
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	return salt, nil
+}
+
+// NormalizeSalt trims leading and trailing whitespace, ASCII and Unicode
+// alike (including non-breaking spaces), and converts the salt to Unicode
+// NFC so visually identical salts hash identically. It is applied only with
+// -normalize-salt: enabling it changes the hash for any salt it alters, so
+// turning it on must be a deliberate, versioned decision.
+func NormalizeSalt(salt string) string {
+	return norm.NFC.String(strings.TrimSpace(salt))
+}
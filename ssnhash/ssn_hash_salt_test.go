@@ -0,0 +1,24 @@
+// File: ssn_hash_salt_test.go
+
+package ssnhash
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGenerateSaltBounds checks that GenerateSalt returns exactly n bytes
+// within its range and rejects lengths outside it without allocating.
+func TestGenerateSaltBounds(t *testing.T) {
+	for _, n := range []int{1, 16, MaxSaltLen} {
+		salt, err := GenerateSalt(n)
+		if err != nil || len(salt) != n {
+			t.Errorf("GenerateSalt(%d) = %d bytes, %v", n, len(salt), err)
+		}
+	}
+	for _, n := range []int{0, -1, MaxSaltLen + 1, math.MaxInt / 2} {
+		if _, err := GenerateSalt(n); err == nil {
+			t.Errorf("GenerateSalt(%d) succeeded, want an error", n)
+		}
+	}
+}
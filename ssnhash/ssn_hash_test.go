@@ -0,0 +1,121 @@
+// File: ssn_hash_test.go
+
+package ssnhash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestHashSSN pins HashSSN to the output of the original ssn_hash command,
+// which printed the SHA-256 of the salt followed by the SSN exactly as
+// given.
+func TestHashSSN(t *testing.T) {
+	tests := []struct {
+		ssn, salt, want string
+	}{
+		{"123456789", "s", "54117038daac78f84ec5919a363ca667137e59dc7f0627367e0de31274972501"},
+		{"123-45-6789", "mysalt", "3d617efe0b015ec73d6b696c617c44b7d2b921afdf1191c17895b973c1cfd5ce"},
+		{"", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, tt := range tests {
+		if got := HashSSN(tt.ssn, tt.salt); got != tt.want {
+			t.Errorf("HashSSN(%q, %q) = %s, want %s", tt.ssn, tt.salt, got, tt.want)
+		}
+		sum := HashSSNBytes([]byte(tt.ssn), []byte(tt.salt))
+		if got := hex.EncodeToString(sum[:]); got != tt.want {
+			t.Errorf("HashSSNBytes(%q, %q) = %s, want %s", tt.ssn, tt.salt, got, tt.want)
+		}
+		if ok, err := VerifySSN(tt.ssn, tt.salt, tt.want); err != nil || !ok {
+			t.Errorf("VerifySSN(%q, %q, %s) = %v, %v, want true, nil", tt.ssn, tt.salt, tt.want, ok, err)
+		}
+	}
+}
+
+// TestHashSSNNormalized checks that the dashed and undashed forms of an
+// SSN hash identically once normalized.
+func TestHashSSNNormalized(t *testing.T) {
+	want := HashSSN("123456789", "s")
+	for _, in := range []string{"123-45-6789", " 123 45 6789 ", "123456789"} {
+		digits, err := NormalizeSSN(in)
+		if err != nil {
+			t.Fatalf("NormalizeSSN(%q): %v", in, err)
+		}
+		if got := HashSSN(digits, "s"); got != want {
+			t.Errorf("HashSSN(NormalizeSSN(%q), s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+// TestHashSSNSep checks the separator vector from the HashSSNSep doc and
+// that an empty separator matches HashSSN.
+func TestHashSSNSep(t *testing.T) {
+	const want = "6ac78c04e065ba9923d50331a6cdc74ca5e6dc92573ceb726a3043fc551bad7c"
+	if got := HashSSNSep("123456789", "s", ":"); got != want {
+		t.Errorf("HashSSNSep(%q, %q, %q) = %s, want %s", "123456789", "s", ":", got, want)
+	}
+	if got, plain := HashSSNSep("123456789", "s", ""), HashSSN("123456789", "s"); got != plain {
+		t.Errorf("HashSSNSep with empty sep = %s, want HashSSN's %s", got, plain)
+	}
+}
+
+// rfc4231 holds the RFC 4231 HMAC-SHA-256 and HMAC-SHA-512 test cases.
+// Case 5, which checks a truncated output, does not apply.
+var rfc4231 = []struct {
+	name           string
+	key, data      []byte
+	sha256, sha512 string
+}{
+	{
+		"case 1", bytes.Repeat([]byte{0x0b}, 20), []byte("Hi There"),
+		"b0344c61d8db38535ca8afceaf0bf12b881dc200c9833da726e9376c2e32cff7",
+		"87aa7cdea5ef619d4ff0b4241a1d6cb02379f4e2ce4ec2787ad0b30545e17cdedaa833b7d6b8a702038b274eaea3f4e4be9d914eeb61f1702e696c203a126854",
+	},
+	{
+		"case 2", []byte("Jefe"), []byte("what do ya want for nothing?"),
+		"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		"164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+	},
+	{
+		"case 3", bytes.Repeat([]byte{0xaa}, 20), bytes.Repeat([]byte{0xdd}, 50),
+		"773ea91e36800e46854db8ebd09181a72959098b3ef8c122d9635514ced565fe",
+		"fa73b0089d56a284efb0f0756c890be9b1b5dbdd8ee81a3655f83e33b2279d39bf3e848279a722c806b485a47e67c807b946a337bee8942674278859e13292fb",
+	},
+	{
+		"case 4", []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25}, bytes.Repeat([]byte{0xcd}, 50),
+		"82558a389a443c0ea4cc819899f2083a85f0faa3e578f8077a2e3ff46729665b",
+		"b0ba465637458c6990e5a8c5f61d4af7e576d97ff94b872de76f8050361ee3dba91ca5c11aa25eb4d679275cc5788063a5f19741120c4f2de2adebeb10a298dd",
+	},
+	{
+		"case 6", bytes.Repeat([]byte{0xaa}, 131), []byte("Test Using Larger Than Block-Size Key - Hash Key First"),
+		"60e431591ee0b67f0d8a26aacbf5b77f8e0bc6213728c5140546040f0ee37f54",
+		"80b24263c7c1a3ebb71493c1dd7be8b49b46d1f41b4aeec1121b013783f8f3526b56d037e05f2598bd0fd2215d6a1e5295e64f73f63f0aec8b915a985d786598",
+	},
+	{
+		"case 7", bytes.Repeat([]byte{0xaa}, 131), []byte("This is a test using a larger than block-size key and a larger than block-size data. The key needs to be hashed before being used by the HMAC algorithm."),
+		"9b09ffa71b942fcb27635fbcd5b0e944bfdc63644f0713938a7f51535c3a35e2",
+		"e37b6a775dc87dbaa4dfa9f96e5e3ffddebd71f8867289865df5a32d20cdc944b6022cac3c4982b10d5eeb55c3e4de15134676fb6de0446065c97440fa8c6a58",
+	},
+}
+
+// TestHMACRFC4231 checks HMACSSN, which keys with the salt, and hmacWith
+// for both hash sizes against the RFC 4231 vectors.
+func TestHMACRFC4231(t *testing.T) {
+	for _, tc := range rfc4231 {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := HMACSSN(string(tc.data), string(tc.key)); got != tc.sha256 {
+				t.Errorf("HMACSSN = %s, want %s", got, tc.sha256)
+			}
+			for algo, want := range map[string]string{"sha256": tc.sha256, "sha512": tc.sha512} {
+				sum, err := hmacWith(algo, tc.key, tc.data)
+				if err != nil {
+					t.Fatalf("hmacWith(%s): %v", algo, err)
+				}
+				if got := hex.EncodeToString(sum); got != want {
+					t.Errorf("hmacWith(%s) = %s, want %s", algo, got, want)
+				}
+			}
+		})
+	}
+}
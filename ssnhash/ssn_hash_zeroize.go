@@ -0,0 +1,33 @@
+// File: ssn_hash_zeroize.go
+
+package ssnhash
+
+// Zeroize overwrites b with zeros. The hashing paths call it (usually via
+// defer) on every byte slice that held an SSN, a salt or their
+// concatenation, once the digest has been computed.
+//
+// Not everything can be wiped. Go strings are immutable, so the values
+// returned by the flag package, CSV fields, scanner lines and the results of
+// NormalizeSSN linger until the garbage collector reclaims them, as do the
+// internal states of the hash.Hash implementations and any copies the
+// runtime makes when growing a slice. The code therefore converts to []byte
+// as early as it can and keeps the sensitive material in byte slices from
+// there on.
+func Zeroize(b []byte) {
+	clear(b)
+}
+
+// concat joins parts into a buffer allocated at its final size, so append
+// never leaves a stale partial copy behind when growing it. The caller must
+// zeroize the result.
+func concat(parts ...[]byte) []byte {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	buf := make([]byte, 0, n)
+	for _, p := range parts {
+		buf = append(buf, p...)
+	}
+	return buf
+}